@@ -3,8 +3,10 @@ package main
 import (
 	"WebMessanger/internal/adapter/handler"
 	"WebMessanger/internal/adapter/postgres"
+	"WebMessanger/internal/adapter/storage"
 	"WebMessanger/internal/app/service"
 	"WebMessanger/pkg/middleware"
+	"context"
 	"database/sql"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
@@ -13,6 +15,8 @@ import (
 
 	"log"
 	"os"
+	"runtime"
+	"time"
 )
 
 func main() {
@@ -28,15 +32,53 @@ func main() {
 	userService := service.NewUserService(repo)
 	authHandler := handler.NewAuthHandler(userService)
 	userHandler := handler.NewUserHandler(userService)
+	mediaRepo := postgres.NewMediaRepo(db)
+	mediaStorage, err := storage.FromEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+	mediaHandler := handler.NewMediaHandler(service.NewMediaService(mediaStorage, mediaRepo, service.NewImagePipeline(runtime.NumCPU())), userService)
+	notificationRepo := postgres.NewNotificationRepo(db)
+	notificationService := service.NewNotificationService(notificationRepo)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	mentionService := service.NewMentionService(postgres.NewMentionRepo(db), repo, notificationRepo)
 	threadRepo := postgres.NewThreadRepo(db)
-	threadService := service.NewThreadService(threadRepo)
+	tagRepo := postgres.NewTagRepo(db)
+	pollRepo := postgres.NewPollRepo(db)
+	linkPreviewWorker := service.NewLinkPreviewWorker(service.NewLinkPreviewFetcher(service.NewPreviewHTTPClient()), postgres.NewLinkPreviewRepo(db))
+	go linkPreviewWorker.Run(context.Background())
+	attachmentValidator := service.NewAttachmentValidator(mediaRepo, service.RequireAltTextFromEnv())
+	communityRepo := postgres.NewCommunityRepo(db)
+	categoryRepo := postgres.NewCategoryRepo(db)
+	moderationService := service.NewModerationService(threadRepo, repo, communityRepo, postgres.NewAuditRepo(db))
+	threadService := service.NewThreadService(threadRepo, tagRepo, mentionService, pollRepo, linkPreviewWorker, attachmentValidator, communityRepo, categoryRepo, moderationService)
 	threadHandler := handler.NewThreadHandler(threadService)
+	communityHandler := handler.NewCommunityHandler(service.NewCommunityService(communityRepo, threadService, threadRepo))
+	categoryHandler := handler.NewCategoryHandler(service.NewCategoryService(categoryRepo, threadRepo, repo))
+	moderationHandler := handler.NewModerationHandler(moderationService)
+	go service.NewThreadScheduler(threadService, 30*time.Second).Run(context.Background())
+	go service.NewThreadReaper(threadService, time.Minute).Run(context.Background())
+	tagService := service.NewTagService(tagRepo, threadRepo)
+	tagHandler := handler.NewTagHandler(tagService)
+	pollHandler := handler.NewPollHandler(service.NewPollService(pollRepo))
+	pinHandler := handler.NewPinHandler(service.NewPinService(postgres.NewPinRepo(db), threadRepo))
+	bookmarkService := service.NewBookmarkService(postgres.NewBookmarkRepo(db), threadRepo)
+	bookmarkHandler := handler.NewBookmarkHandler(bookmarkService)
+	followRepo := postgres.NewFollowRepo(db)
+	followHandler := handler.NewFollowHandler(service.NewFollowService(followRepo, repo))
+	threadAccess := service.NewThreadAccess(threadRepo, followRepo)
 	commentRepo := postgres.NewCommentRepo(db)
-	commentService := service.NewCommentService(commentRepo)
+	commentService := service.NewCommentService(commentRepo, mentionService, threadAccess, moderationService)
 	commentHandler := handler.NewCommentHandler(commentService, userService)
+	go service.BackfillContentHTML(threadService, commentService)
+	go service.NewDeletedPurger(threadService, commentService, time.Hour).Run(context.Background())
+	revisionHandler := handler.NewRevisionHandler(service.NewRevisionService(postgres.NewRevisionRepo(db), commentRepo, threadAccess))
 	likeRepo := postgres.NewLikeRepo(db)
-	likeService := service.NewLikeService(likeRepo)
-	likeHandler := handler.NewLikeHandler(likeService, userService, threadService)
+	likeService := service.NewLikeService(likeRepo, commentRepo, threadAccess, service.ReactionSetFromEnv())
+	likeHandler := handler.NewLikeHandler(likeService, userService, threadService, commentService)
+	blockRepo := postgres.NewBlockRepo(db)
+	blockHandler := handler.NewBlockHandler(service.NewBlockService(blockRepo, repo))
+	messageHandler := handler.NewMessageHandler(service.NewMessageService(postgres.NewMessageRepo(db), blockRepo, repo))
 
 	r := gin.Default()
 	r.Use(cors.New(cors.Config{
@@ -60,24 +102,76 @@ func main() {
 	r.POST("/login", authHandler.Login)
 	r.GET("/search", userHandler.SearchUsers)
 	r.GET("/verify", authHandler.VerifyEmail)
+	if local, ok := mediaStorage.(*storage.LocalStorage); ok {
+		r.Static(storage.URLPrefix, local.Dir())
+	}
 
 	protected := r.Group("/user", middleware.JWTMiddleware())
 	{
 		protected.GET("/:id", userHandler.GetUserByID)
 		protected.GET("/me", userHandler.GetMe)
 		protected.PUT("/me", userHandler.UpdateProfile)
+		protected.POST("/me/avatar", mediaHandler.UploadAvatar)
+		protected.POST("/media", mediaHandler.UploadThreadMedia)
 		protected.POST("/threads", threadHandler.Create)
 		protected.PUT("/threads/:id", threadHandler.Update)
 		protected.DELETE("/threads/:id", threadHandler.Delete)
 		protected.GET("/threads", threadHandler.GetAll)
+		protected.GET("/threads/drafts", threadHandler.GetDrafts)
+		protected.POST("/threads/:id/publish", threadHandler.Publish)
 		protected.GET("/threads/:id", threadHandler.GetById)
+		protected.POST("/threads/:id/quote", threadHandler.Quote)
+		protected.POST("/threads/:id/repost", threadHandler.Repost)
+		protected.DELETE("/threads/:id/repost", threadHandler.Unrepost)
+		protected.POST("/threads/:id/pin", pinHandler.Pin)
+		protected.DELETE("/threads/:id/pin", pinHandler.Unpin)
+		protected.PUT("/threads/:id/sensitive", moderationHandler.SetSensitive)
+		protected.POST("/threads/:id/lock", moderationHandler.Lock)
+		protected.DELETE("/threads/:id/lock", moderationHandler.Unlock)
+		protected.GET("/threads/:id/audit", moderationHandler.GetAuditLog)
+		protected.PUT("/pins", pinHandler.Reorder)
+		protected.POST("/threads/:id/poll/votes", pollHandler.Vote)
+		protected.PUT("/threads/:id/poll/votes", pollHandler.ChangeVote)
 		protected.GET("/users/:user_id/threads", threadHandler.GetByUser)
 		protected.GET("/users/:user_id/posts", threadHandler.GetByUser)
+		protected.POST("/users/:user_id/follow", followHandler.Follow)
+		protected.DELETE("/users/:user_id/follow", followHandler.Unfollow)
+		protected.GET("/users/:user_id/followers", followHandler.GetFollowers)
+		protected.GET("/users/:user_id/following", followHandler.GetFollowing)
+		protected.POST("/users/:user_id/block", blockHandler.Block)
+		protected.DELETE("/users/:user_id/block", blockHandler.Unblock)
+		protected.GET("/blocks", blockHandler.GetBlocked)
+		protected.POST("/communities", communityHandler.Create)
+		protected.GET("/communities", communityHandler.List)
+		protected.GET("/communities/:id", communityHandler.Get)
+		protected.PUT("/communities/:id", communityHandler.Update)
+		protected.DELETE("/communities/:id", communityHandler.Delete)
+		protected.POST("/communities/:id/join", communityHandler.Join)
+		protected.POST("/communities/:id/leave", communityHandler.Leave)
+		protected.GET("/communities/:id/members", communityHandler.GetMembers)
+		protected.DELETE("/communities/:id/members/:user_id", communityHandler.RemoveMember)
+		protected.DELETE("/communities/:id/bans/:user_id", communityHandler.Unban)
+		protected.PUT("/communities/:id/moderators/:user_id", communityHandler.AddModerator)
+		protected.DELETE("/communities/:id/moderators/:user_id", communityHandler.RemoveModerator)
+		protected.GET("/communities/:id/threads", communityHandler.GetThreads)
+		protected.DELETE("/communities/:id/threads/:thread_id", communityHandler.RemoveThread)
+		protected.GET("/categories", categoryHandler.List)
+		protected.POST("/categories", categoryHandler.Create)
+		protected.PUT("/categories/:id", categoryHandler.Update)
+		protected.DELETE("/categories/:id", categoryHandler.Delete)
+		protected.GET("/categories/:id/threads", categoryHandler.GetThreads)
+		protected.GET("/tags", tagHandler.Autocomplete)
+		protected.GET("/tags/:tag", tagHandler.GetThreads)
+		protected.GET("/notifications", notificationHandler.GetMine)
+		protected.POST("/notifications/read", notificationHandler.MarkAllRead)
+		protected.POST("/notifications/:id/read", notificationHandler.MarkRead)
 	}
 	commentRoutes := protected.Group("/comments")
 	{
 		commentRoutes.POST("/:thread_id", commentHandler.Create)
 		commentRoutes.GET("/:thread_id", commentHandler.GetByThread)
+		commentRoutes.GET("/:thread_id/replies/:comment_id", commentHandler.GetReplies)
+		commentRoutes.PUT("/:comment_id", commentHandler.Update)
 		commentRoutes.DELETE("/:comment_id", commentHandler.Delete)
 	}
 	likeRoutes := protected.Group("/likes")
@@ -86,6 +180,46 @@ func main() {
 		likeRoutes.DELETE("/:thread_id", likeHandler.RemoveLike)
 		likeRoutes.GET("/:thread_id", likeHandler.GetLikesByThread)
 		likeRoutes.GET("/user/:user_id", likeHandler.GetLikesByUser)
+		likeRoutes.POST("/comments/:comment_id", likeHandler.CreateCommentLike)
+		likeRoutes.DELETE("/comments/:comment_id", likeHandler.RemoveCommentLike)
+		likeRoutes.GET("/comments/:comment_id", likeHandler.GetLikesByComment)
+	}
+	bookmarkRoutes := protected.Group("/bookmarks")
+	{
+		bookmarkRoutes.GET("", bookmarkHandler.List)
+		bookmarkRoutes.GET("/folders", bookmarkHandler.GetFolders)
+		bookmarkRoutes.POST("/:thread_id", bookmarkHandler.Add)
+		bookmarkRoutes.DELETE("/:thread_id", bookmarkHandler.Remove)
+	}
+	reactionRoutes := protected.Group("/reactions")
+	{
+		reactionRoutes.GET("", likeHandler.GetReactionSet)
+		reactionRoutes.PUT("/threads/:thread_id", likeHandler.SetThreadReaction)
+		reactionRoutes.DELETE("/threads/:thread_id", likeHandler.RemoveThreadReaction)
+		reactionRoutes.GET("/threads/:thread_id", likeHandler.GetThreadReactions)
+		reactionRoutes.PUT("/comments/:comment_id", likeHandler.SetCommentReaction)
+		reactionRoutes.DELETE("/comments/:comment_id", likeHandler.RemoveCommentReaction)
+		reactionRoutes.GET("/comments/:comment_id", likeHandler.GetCommentReactions)
+	}
+	deletedRoutes := protected.Group("/deleted")
+	{
+		deletedRoutes.GET("/threads", threadHandler.GetDeleted)
+		deletedRoutes.POST("/threads/:thread_id/restore", threadHandler.Restore)
+		deletedRoutes.GET("/comments", commentHandler.GetDeleted)
+		deletedRoutes.POST("/comments/:comment_id/restore", commentHandler.Restore)
+	}
+	conversationRoutes := protected.Group("/conversations")
+	{
+		conversationRoutes.POST("", messageHandler.StartConversation)
+		conversationRoutes.GET("", messageHandler.GetConversations)
+		conversationRoutes.GET("/:id/messages", messageHandler.GetMessages)
+		conversationRoutes.POST("/:id/messages", messageHandler.Send)
+		conversationRoutes.POST("/:id/read", messageHandler.MarkRead)
+	}
+	revisionRoutes := protected.Group("/revisions")
+	{
+		revisionRoutes.GET("/threads/:thread_id", revisionHandler.GetThreadRevisions)
+		revisionRoutes.GET("/comments/:comment_id", revisionHandler.GetCommentRevisions)
 	}
 
 	port := os.Getenv("PORT")
@@ -26,20 +26,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if input.Name == "" || input.Password == "" || input.Email == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input"})
-		return
-	}
-	if len(input.Name) < 3 || len(input.Name) > 16 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid name"})
-		return
-	}
-	if len(input.Password) < 5 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Password too short"})
-		return
-	}
 
 	user, err := h.uc.Register(&input)
+	if respondValidationError(c, err) {
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
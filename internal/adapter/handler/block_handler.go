@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"WebMessanger/internal/app/service"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type BlockHandler struct {
+	uc usecase.BlockUsecase
+}
+
+func NewBlockHandler(uc usecase.BlockUsecase) *BlockHandler {
+	return &BlockHandler{uc: uc}
+}
+func (h *BlockHandler) Block(c *gin.Context) {
+	blockedID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	err = h.uc.Block(userID.(uuid.UUID), blockedID)
+	switch {
+	case errors.Is(err, service.ErrBlockSelf):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "User has been blocked"})
+	}
+}
+func (h *BlockHandler) Unblock(c *gin.Context) {
+	blockedID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	if err := h.uc.Unblock(userID.(uuid.UUID), blockedID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "User has been unblocked"})
+}
+func (h *BlockHandler) GetBlocked(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	users, err := h.uc.GetBlocked(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
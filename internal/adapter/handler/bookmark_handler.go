@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"WebMessanger/internal/app/service"
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type BookmarkHandler struct {
+	uc usecase.BookmarkUsecase
+}
+
+func NewBookmarkHandler(uc usecase.BookmarkUsecase) *BookmarkHandler {
+	return &BookmarkHandler{uc: uc}
+}
+func (h *BookmarkHandler) Add(c *gin.Context) {
+	threadID, err := uuid.Parse(c.Param("thread_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	var body struct {
+		Folder string `json:"folder"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authorized"})
+		return
+	}
+	bookmark := &model.Bookmark{UserID: userID.(uuid.UUID), ThreadID: threadID, Folder: body.Folder}
+	created, err := h.uc.Add(bookmark)
+	switch {
+	case errors.Is(err, service.ErrThreadNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidFolderName):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusCreated, created)
+	}
+}
+func (h *BookmarkHandler) Remove(c *gin.Context) {
+	threadID, err := uuid.Parse(c.Param("thread_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authorized"})
+		return
+	}
+	if err := h.uc.Remove(threadID, userID.(uuid.UUID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Bookmark has been removed"})
+}
+func (h *BookmarkHandler) List(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authorized"})
+		return
+	}
+	var folder *string
+	if val, ok := c.GetQuery("folder"); ok {
+		folder = &val
+	}
+	bookmarks, next, err := h.uc.List(userID.(uuid.UUID), folder, c.Query("cursor"), queryInt(c, "limit", 0))
+	if errors.Is(err, service.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"bookmarks": bookmarks, "next_cursor": next})
+}
+func (h *BookmarkHandler) GetFolders(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authorized"})
+		return
+	}
+	folders, err := h.uc.GetFolders(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"folders": folders})
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"WebMessanger/internal/app/service"
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type CategoryHandler struct {
+	uc usecase.CategoryUsecase
+}
+
+func NewCategoryHandler(uc usecase.CategoryUsecase) *CategoryHandler {
+	return &CategoryHandler{uc: uc}
+}
+
+type categoryRequest struct {
+	Slug        string `json:"slug"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Position    int    `json:"position"`
+}
+
+func (h *CategoryHandler) List(c *gin.Context) {
+	categories, err := h.uc.List()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"categories": categories})
+}
+func (h *CategoryHandler) GetThreads(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+		return
+	}
+	viewerID, _ := c.Get("user_id")
+	offset := queryInt(c, "offset", 0)
+	category, threads, err := h.uc.GetThreads(id, viewerID.(uuid.UUID), queryInt(c, "limit", 0), offset)
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"category": category, "threads": threads, "next_offset": offset + len(threads)})
+}
+func (h *CategoryHandler) Create(c *gin.Context) {
+	var body categoryRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	category, err := h.uc.Create(userID.(uuid.UUID), &model.Category{
+		Slug: body.Slug, Name: body.Name, Description: body.Description, Position: body.Position,
+	})
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, category)
+}
+func (h *CategoryHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+		return
+	}
+	var body categoryRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	category, err := h.uc.Update(userID.(uuid.UUID), &model.Category{
+		ID: id, Slug: body.Slug, Name: body.Name, Description: body.Description, Position: body.Position,
+	})
+	if err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, category)
+}
+func (h *CategoryHandler) Delete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	if err := h.uc.Delete(userID.(uuid.UUID), id); err != nil {
+		respondCategoryError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Category has been deleted"})
+}
+
+func respondCategoryError(c *gin.Context, err error) {
+	if respondValidationError(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotAdmin):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCategorySlugTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"WebMessanger/internal/app/service"
 	"WebMessanger/internal/model"
 	"WebMessanger/internal/usecase"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"net/http"
@@ -46,6 +48,21 @@ func (h *CommentHandler) Create(c *gin.Context) {
 	comment.UserName = user.Name
 
 	created, err := h.uc.Create(&comment)
+	if respondValidationError(c, err) {
+		return
+	}
+	if errors.Is(err, service.ErrParentCommentNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrThreadNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrReplyNotAllowed) || errors.Is(err, service.ErrThreadLocked) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -53,6 +70,37 @@ func (h *CommentHandler) Create(c *gin.Context) {
 	c.JSON(http.StatusCreated, created)
 }
 
+// Update edits the caller's own comment. Only the content can change.
+func (h *CommentHandler) Update(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return
+	}
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	updated, err := h.uc.Update(id, userID.(uuid.UUID), req.Content)
+	if respondValidationError(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrCommentNotFound), errors.Is(err, service.ErrThreadNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotCommentOwner), errors.Is(err, service.ErrThreadLocked):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, updated)
+	}
+}
+
 func (h *CommentHandler) Delete(c *gin.Context) {
 	idStr := c.Param("comment_id")
 	id, err := uuid.Parse(idStr)
@@ -61,11 +109,49 @@ func (h *CommentHandler) Delete(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := h.uc.Delete(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+	userID, _ := c.Get("user_id")
+	err = h.uc.Delete(id, userID.(uuid.UUID))
+	switch {
+	case errors.Is(err, service.ErrCommentNotFound), errors.Is(err, service.ErrThreadNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotCommentOwner):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusNoContent, gin.H{"message": "comment deleted successfully"})
+	}
+}
+
+// GetDeleted lists the caller's recently deleted comments.
+func (h *CommentHandler) GetDeleted(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	comments, err := h.uc.GetDeleted(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, comments)
+}
+
+// Restore undeletes one of the caller's recently deleted comments.
+func (h *CommentHandler) Restore(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	comment, err := h.uc.Restore(id, userID.(uuid.UUID))
+	if errors.Is(err, service.ErrCommentNotFound) || errors.Is(err, service.ErrThreadNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusNoContent, gin.H{"message": "comment deleted successfully"})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, comment)
 }
 func (h *CommentHandler) GetByThread(c *gin.Context) {
 	threadIDStr := c.Param("thread_id")
@@ -74,10 +160,44 @@ func (h *CommentHandler) GetByThread(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
 		return
 	}
-	comments, err := h.uc.GetByThread(threadID)
+	viewerID, _ := c.Get("user_id")
+	maxDepth := queryInt(c, "max_depth", 0)
+	repliesLimit := queryInt(c, "replies_limit", 0)
+	comments, err := h.uc.GetByThread(threadID, viewerID.(uuid.UUID), maxDepth, repliesLimit)
+	if errors.Is(err, service.ErrThreadNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, comments)
 }
+func (h *CommentHandler) GetReplies(c *gin.Context) {
+	threadID, err := uuid.Parse(c.Param("thread_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	commentIDStr := c.Param("comment_id")
+	commentID, err := uuid.Parse(commentIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return
+	}
+	viewerID, _ := c.Get("user_id")
+	offset := queryInt(c, "offset", 0)
+	limit := queryInt(c, "limit", 0)
+	maxDepth := queryInt(c, "max_depth", 0)
+	replies, hasMore, err := h.uc.GetReplies(threadID, commentID, viewerID.(uuid.UUID), offset, limit, maxDepth)
+	if errors.Is(err, service.ErrCommentNotFound) || errors.Is(err, service.ErrThreadNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"replies": replies, "has_more": hasMore, "next_offset": offset + len(replies)})
+}
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"WebMessanger/internal/app/service"
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type CommunityHandler struct {
+	uc usecase.CommunityUsecase
+}
+
+func NewCommunityHandler(uc usecase.CommunityUsecase) *CommunityHandler {
+	return &CommunityHandler{uc: uc}
+}
+func (h *CommunityHandler) Create(c *gin.Context) {
+	var body struct {
+		Name        string `json:"name"`
+		Description string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	community, err := h.uc.Create(&model.Community{Name: body.Name, Description: body.Description, OwnerID: userID.(uuid.UUID)})
+	if err != nil {
+		respondCommunityError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, community)
+}
+func (h *CommunityHandler) List(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	offset := queryInt(c, "offset", 0)
+	communities, err := h.uc.List(c.Query("q"), userID.(uuid.UUID), queryInt(c, "limit", 0), offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"communities": communities, "next_offset": offset + len(communities)})
+}
+func (h *CommunityHandler) Get(c *gin.Context) {
+	id, ok := communityID(c)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	community, err := h.uc.Get(id, userID.(uuid.UUID))
+	if err != nil {
+		respondCommunityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, community)
+}
+func (h *CommunityHandler) Update(c *gin.Context) {
+	id, ok := communityID(c)
+	if !ok {
+		return
+	}
+	var body struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	community, err := h.uc.Update(id, userID.(uuid.UUID), body.Name, body.Description)
+	if err != nil {
+		respondCommunityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, community)
+}
+func (h *CommunityHandler) Delete(c *gin.Context) {
+	id, ok := communityID(c)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	if err := h.uc.Delete(id, userID.(uuid.UUID)); err != nil {
+		respondCommunityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Community has been deleted"})
+}
+func (h *CommunityHandler) Join(c *gin.Context) {
+	id, ok := communityID(c)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	if err := h.uc.Join(id, userID.(uuid.UUID)); err != nil {
+		respondCommunityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Joined the community"})
+}
+func (h *CommunityHandler) Leave(c *gin.Context) {
+	id, ok := communityID(c)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	if err := h.uc.Leave(id, userID.(uuid.UUID)); err != nil {
+		respondCommunityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Left the community"})
+}
+func (h *CommunityHandler) GetMembers(c *gin.Context) {
+	id, ok := communityID(c)
+	if !ok {
+		return
+	}
+	offset := queryInt(c, "offset", 0)
+	members, err := h.uc.GetMembers(id, queryInt(c, "limit", 0), offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"members": members, "next_offset": offset + len(members)})
+}
+func (h *CommunityHandler) RemoveMember(c *gin.Context) {
+	id, ok := communityID(c)
+	if !ok {
+		return
+	}
+	memberID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	if err := h.uc.RemoveMember(id, userID.(uuid.UUID), memberID); err != nil {
+		respondCommunityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member has been removed"})
+}
+func (h *CommunityHandler) Unban(c *gin.Context) {
+	id, ok := communityID(c)
+	if !ok {
+		return
+	}
+	memberID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	if err := h.uc.Unban(id, userID.(uuid.UUID), memberID); err != nil {
+		respondCommunityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Ban has been lifted"})
+}
+func (h *CommunityHandler) AddModerator(c *gin.Context) {
+	h.setModerator(c, true)
+}
+func (h *CommunityHandler) RemoveModerator(c *gin.Context) {
+	h.setModerator(c, false)
+}
+func (h *CommunityHandler) setModerator(c *gin.Context, moderator bool) {
+	id, ok := communityID(c)
+	if !ok {
+		return
+	}
+	memberID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	if err := h.uc.SetModerator(id, userID.(uuid.UUID), memberID, moderator); err != nil {
+		respondCommunityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Moderators have been updated"})
+}
+func (h *CommunityHandler) GetThreads(c *gin.Context) {
+	id, ok := communityID(c)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	offset := queryInt(c, "offset", 0)
+	threads, err := h.uc.GetThreads(id, userID.(uuid.UUID), queryInt(c, "limit", 0), offset)
+	if err != nil {
+		respondCommunityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"threads": threads, "next_offset": offset + len(threads)})
+}
+func (h *CommunityHandler) RemoveThread(c *gin.Context) {
+	id, ok := communityID(c)
+	if !ok {
+		return
+	}
+	threadID, err := uuid.Parse(c.Param("thread_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	if err := h.uc.RemoveThread(id, userID.(uuid.UUID), threadID); err != nil {
+		respondCommunityError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Thread has been removed from the community"})
+}
+
+func communityID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid community id"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func respondCommunityError(c *gin.Context, err error) {
+	if respondValidationError(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrCommunityNotFound), errors.Is(err, service.ErrMemberNotFound),
+		errors.Is(err, service.ErrThreadNotFound), errors.Is(err, service.ErrBanNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotCommunityModerator), errors.Is(err, service.ErrNotCommunityOwner),
+		errors.Is(err, service.ErrBannedFromCommunity):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrCommunityNameTaken), errors.Is(err, service.ErrCommunityOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
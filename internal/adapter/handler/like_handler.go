@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"WebMessanger/internal/app/service"
 	"WebMessanger/internal/model"
 	"WebMessanger/internal/usecase"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"log"
@@ -10,32 +12,86 @@ import (
 )
 
 type LikeHandler struct {
-	uc            usecase.LikeUsecase
-	userUsecase   usecase.UserUsecase
-	threadUsecase usecase.ThreadUsecase
+	uc             usecase.LikeUsecase
+	userUsecase    usecase.UserUsecase
+	threadUsecase  usecase.ThreadUsecase
+	commentUsecase usecase.CommentUsecase
 }
 
-func NewLikeHandler(uc usecase.LikeUsecase, userUsecase usecase.UserUsecase, threadUsecase usecase.ThreadUsecase) *LikeHandler {
-	return &LikeHandler{uc, userUsecase, threadUsecase}
+func NewLikeHandler(uc usecase.LikeUsecase, userUsecase usecase.UserUsecase, threadUsecase usecase.ThreadUsecase, commentUsecase usecase.CommentUsecase) *LikeHandler {
+	return &LikeHandler{uc, userUsecase, threadUsecase, commentUsecase}
 }
 func (h *LikeHandler) Create(c *gin.Context) {
-
-	var like model.Like
-	log.Println("Adding like from", like.UserID, "to", like.ThreadID)
-
 	threadIDStr := c.Param("thread_id")
 	threadID, err := uuid.Parse(threadIDStr)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
 		return
 	}
-	like.ThreadID = threadID
+	h.addLike(c, model.LikeTargetThread, threadID, model.DefaultReaction, h.uc.AddLike)
+}
+func (h *LikeHandler) CreateCommentLike(c *gin.Context) {
+	commentIDStr := c.Param("comment_id")
+	commentID, err := uuid.Parse(commentIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return
+	}
+	if _, err := h.commentUsecase.GetByID(commentID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+		return
+	}
+	h.addLike(c, model.LikeTargetComment, commentID, model.DefaultReaction, h.uc.AddLike)
+}
+func (h *LikeHandler) SetThreadReaction(c *gin.Context) {
+	threadIDStr := c.Param("thread_id")
+	threadID, err := uuid.Parse(threadIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	var body struct {
+		Reaction string `json:"reaction"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Reaction == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or empty reaction"})
+		return
+	}
+	h.addLike(c, model.LikeTargetThread, threadID, body.Reaction, h.uc.SetReaction)
+}
+func (h *LikeHandler) SetCommentReaction(c *gin.Context) {
+	commentIDStr := c.Param("comment_id")
+	commentID, err := uuid.Parse(commentIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return
+	}
+	var body struct {
+		Reaction string `json:"reaction"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil || body.Reaction == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or empty reaction"})
+		return
+	}
+	if _, err := h.commentUsecase.GetByID(commentID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+		return
+	}
+	h.addLike(c, model.LikeTargetComment, commentID, body.Reaction, h.uc.SetReaction)
+}
+func (h *LikeHandler) addLike(c *gin.Context, targetType string, targetID uuid.UUID, reaction string,
+	save func(*model.Like) (*model.Like, error)) {
+	var like model.Like
+	like.TargetType = targetType
+	like.TargetID = targetID
+	like.Reaction = reaction
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authorized"})
 		return
 	}
 	like.UserID = userID.(uuid.UUID)
+	log.Println("Adding like from", like.UserID, "to", like.TargetType, like.TargetID)
 
 	user, err := h.userUsecase.GetUserByID(like.UserID)
 	if err != nil {
@@ -44,7 +100,19 @@ func (h *LikeHandler) Create(c *gin.Context) {
 	}
 	like.UserName = user.Name
 
-	created, err := h.uc.AddLike(&like)
+	created, err := save(&like)
+	if errors.Is(err, service.ErrInvalidReaction) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "allowed": h.uc.AllowedReactions()})
+		return
+	}
+	if errors.Is(err, service.ErrThreadNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrThreadLocked) || errors.Is(err, service.ErrReplyNotAllowed) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -58,12 +126,41 @@ func (h *LikeHandler) RemoveLike(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	h.removeLike(c, model.LikeTargetThread, threadID, h.uc.RemoveLike)
+}
+func (h *LikeHandler) RemoveCommentLike(c *gin.Context) {
+	commentIDStr := c.Param("comment_id")
+	commentID, err := uuid.Parse(commentIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.removeLike(c, model.LikeTargetComment, commentID, h.uc.RemoveLike)
+}
+func (h *LikeHandler) RemoveThreadReaction(c *gin.Context) {
+	threadID, err := uuid.Parse(c.Param("thread_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	h.removeLike(c, model.LikeTargetThread, threadID, h.uc.RemoveReaction)
+}
+func (h *LikeHandler) RemoveCommentReaction(c *gin.Context) {
+	commentID, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return
+	}
+	h.removeLike(c, model.LikeTargetComment, commentID, h.uc.RemoveReaction)
+}
+func (h *LikeHandler) removeLike(c *gin.Context, targetType string, targetID uuid.UUID,
+	remove func(targetType string, targetID uuid.UUID, userID uuid.UUID) error) {
 	userID, exists := c.Get("user_id")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authorized"})
 		return
 	}
-	err = h.uc.RemoveLike(threadID, userID.(uuid.UUID))
+	err := remove(targetType, targetID, userID.(uuid.UUID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -77,8 +174,42 @@ func (h *LikeHandler) GetLikesByThread(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
 		return
 	}
+	if c.Query("aggregate") == "true" {
+		h.reactionSummary(c, model.LikeTargetThread, threadID)
+		return
+	}
 
-	likes, err := h.uc.GetLikesByThread(threadID)
+	viewerID, _ := c.Get("user_id")
+	likes, err := h.uc.GetLikesByThread(threadID, viewerID.(uuid.UUID))
+	if errors.Is(err, service.ErrThreadNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, likes)
+}
+func (h *LikeHandler) GetLikesByComment(c *gin.Context) {
+	commentIDStr := c.Param("comment_id")
+	commentID, err := uuid.Parse(commentIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return
+	}
+	if c.Query("aggregate") == "true" {
+		h.reactionSummary(c, model.LikeTargetComment, commentID)
+		return
+	}
+
+	viewerID, _ := c.Get("user_id")
+	likes, err := h.uc.GetLikesByComment(commentID, viewerID.(uuid.UUID))
+	if errors.Is(err, service.ErrThreadNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -102,3 +233,41 @@ func (h *LikeHandler) GetLikesByUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, likes)
 }
+func (h *LikeHandler) GetThreadReactions(c *gin.Context) {
+	threadIDStr := c.Param("thread_id")
+	threadID, err := uuid.Parse(threadIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	h.reactionSummary(c, model.LikeTargetThread, threadID)
+}
+func (h *LikeHandler) GetCommentReactions(c *gin.Context) {
+	commentIDStr := c.Param("comment_id")
+	commentID, err := uuid.Parse(commentIDStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return
+	}
+	h.reactionSummary(c, model.LikeTargetComment, commentID)
+}
+func (h *LikeHandler) reactionSummary(c *gin.Context, targetType string, targetID uuid.UUID) {
+	viewerID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authorized"})
+		return
+	}
+	summary, err := h.uc.GetReactionSummary(targetType, targetID, viewerID.(uuid.UUID))
+	if errors.Is(err, service.ErrThreadNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, summary)
+}
+func (h *LikeHandler) GetReactionSet(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"reactions": h.uc.AllowedReactions()})
+}
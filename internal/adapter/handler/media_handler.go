@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"WebMessanger/internal/app/service"
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"log"
+	"net/http"
+)
+
+type MediaHandler struct {
+	uc          usecase.MediaUsecase
+	userUsecase usecase.UserUsecase
+}
+
+func NewMediaHandler(uc usecase.MediaUsecase, userUsecase usecase.UserUsecase) *MediaHandler {
+	return &MediaHandler{uc: uc, userUsecase: userUsecase}
+}
+
+// UploadThreadMedia accepts a multipart "file" and returns a URL to use as a
+// thread's media_url.
+func (h *MediaHandler) UploadThreadMedia(c *gin.Context) {
+	media, ok := h.upload(c, model.MediaKindThread)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusCreated, media)
+}
+
+// UploadAvatar stores the uploaded image and makes it the caller's avatar.
+func (h *MediaHandler) UploadAvatar(c *gin.Context) {
+	media, ok := h.upload(c, model.MediaKindAvatar)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	user, err := h.userUsecase.GetUserByID(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	user.AvatarURL = media.URL
+	if err := h.userUsecase.UpdateProfile(user); err != nil {
+		if err := h.uc.Discard(c.Request.Context(), media); err != nil {
+			log.Println("Failed to discard unused avatar", media.URL+":", err)
+		}
+		if respondValidationError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, media)
+}
+
+func (h *MediaHandler) upload(c *gin.Context, kind string) (*model.Media, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authorized"})
+		return nil, false
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxVideoUploadSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "missing or oversized file"})
+		return nil, false
+	}
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	defer file.Close()
+
+	media, err := h.uc.Upload(c.Request.Context(), kind, userID.(uuid.UUID), file, header.Size)
+	switch {
+	case errors.Is(err, service.ErrUnsupportedMedia):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrMediaTooLarge), errors.Is(err, service.ErrImageTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		return media, true
+	}
+	return nil, false
+}
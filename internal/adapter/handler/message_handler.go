@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"WebMessanger/internal/app/service"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type MessageHandler struct {
+	uc usecase.MessageUsecase
+}
+
+func NewMessageHandler(uc usecase.MessageUsecase) *MessageHandler {
+	return &MessageHandler{uc: uc}
+}
+
+// StartConversation opens, or returns the existing, conversation with the
+// user given in the body.
+func (h *MessageHandler) StartConversation(c *gin.Context) {
+	var req struct {
+		UserID uuid.UUID `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	conversation, err := h.uc.StartConversation(userID.(uuid.UUID), req.UserID)
+	if err != nil {
+		respondMessageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, conversation)
+}
+
+// GetConversations lists the caller's conversations with their latest
+// message and unread count.
+func (h *MessageHandler) GetConversations(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	offset := queryInt(c, "offset", 0)
+	conversations, err := h.uc.GetConversations(userID.(uuid.UUID), queryInt(c, "limit", 0), offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"conversations": conversations, "next_offset": offset + len(conversations)})
+}
+
+func (h *MessageHandler) Send(c *gin.Context) {
+	conversationID, ok := conversationIDParam(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	message, err := h.uc.Send(conversationID, userID.(uuid.UUID), req.Content)
+	if err != nil {
+		respondMessageError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, message)
+}
+
+// GetMessages returns a page of history, newest first; pass next_cursor as
+// cursor to load older messages.
+func (h *MessageHandler) GetMessages(c *gin.Context) {
+	conversationID, ok := conversationIDParam(c)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	messages, next, err := h.uc.GetMessages(conversationID, userID.(uuid.UUID), c.Query("cursor"), queryInt(c, "limit", 0))
+	if err != nil {
+		respondMessageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"messages": messages, "next_cursor": next})
+}
+
+func (h *MessageHandler) MarkRead(c *gin.Context) {
+	conversationID, ok := conversationIDParam(c)
+	if !ok {
+		return
+	}
+	userID, _ := c.Get("user_id")
+	if err := h.uc.MarkRead(conversationID, userID.(uuid.UUID)); err != nil {
+		respondMessageError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Conversation marked as read"})
+}
+
+func conversationIDParam(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid conversation id"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func respondMessageError(c *gin.Context, err error) {
+	if respondValidationError(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrMessageSelf), errors.Is(err, service.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUserBlocked):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrConversationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"WebMessanger/internal/app/service"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type ModerationHandler struct {
+	uc usecase.ModerationUsecase
+}
+
+func NewModerationHandler(uc usecase.ModerationUsecase) *ModerationHandler {
+	return &ModerationHandler{uc: uc}
+}
+func (h *ModerationHandler) SetSensitive(c *gin.Context) {
+	threadID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	var body struct {
+		Sensitive      bool   `json:"sensitive"`
+		ContentWarning string `json:"content_warning"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authorized"})
+		return
+	}
+	thread, err := h.uc.SetSensitive(threadID, userID.(uuid.UUID), body.Sensitive, body.ContentWarning)
+	if respondValidationError(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrThreadNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotModerator), errors.Is(err, service.ErrSensitiveLocked):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, thread)
+	}
+}
+func (h *ModerationHandler) Lock(c *gin.Context) {
+	h.setLocked(c, true)
+}
+func (h *ModerationHandler) Unlock(c *gin.Context) {
+	h.setLocked(c, false)
+}
+func (h *ModerationHandler) setLocked(c *gin.Context, locked bool) {
+	threadID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	thread, err := h.uc.SetLocked(threadID, userID.(uuid.UUID), locked)
+	switch {
+	case errors.Is(err, service.ErrThreadNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotModerator), errors.Is(err, service.ErrLockedByModerator):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, thread)
+	}
+}
+func (h *ModerationHandler) GetAuditLog(c *gin.Context) {
+	threadID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	entries, err := h.uc.GetAuditLog(threadID, userID.(uuid.UUID))
+	switch {
+	case errors.Is(err, service.ErrThreadNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotModerator):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"entries": entries})
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"WebMessanger/internal/app/service"
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type PollHandler struct {
+	uc usecase.PollUsecase
+}
+
+func NewPollHandler(uc usecase.PollUsecase) *PollHandler {
+	return &PollHandler{uc: uc}
+}
+func (h *PollHandler) Vote(c *gin.Context) {
+	h.vote(c, h.uc.Vote)
+}
+func (h *PollHandler) ChangeVote(c *gin.Context) {
+	h.vote(c, h.uc.ChangeVote)
+}
+func (h *PollHandler) vote(c *gin.Context, cast func(threadID, userID uuid.UUID, optionIDs []uuid.UUID) (*model.Poll, error)) {
+	threadID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	var body struct {
+		OptionIDs []uuid.UUID `json:"option_ids"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authorized"})
+		return
+	}
+	poll, err := cast(threadID, userID.(uuid.UUID), body.OptionIDs)
+	switch {
+	case errors.Is(err, service.ErrPollNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrPollClosed), errors.Is(err, service.ErrAlreadyVoted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidPollVote):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, poll)
+	}
+}
@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+// queryInt reads an integer query parameter, falling back to def when it is
+// missing or malformed.
+func queryInt(c *gin.Context, name string, def int) int {
+	val, err := strconv.Atoi(c.Query(name))
+	if err != nil {
+		return def
+	}
+	return val
+}
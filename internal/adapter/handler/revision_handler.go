@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"WebMessanger/internal/app/service"
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type RevisionHandler struct {
+	uc usecase.RevisionUsecase
+}
+
+func NewRevisionHandler(uc usecase.RevisionUsecase) *RevisionHandler {
+	return &RevisionHandler{uc: uc}
+}
+
+// GetThreadRevisions lists the earlier versions of a thread, oldest first.
+func (h *RevisionHandler) GetThreadRevisions(c *gin.Context) {
+	threadID, err := uuid.Parse(c.Param("thread_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	viewerID, _ := c.Get("user_id")
+	revisions, err := h.uc.GetThreadRevisions(threadID, viewerID.(uuid.UUID))
+	respondRevisions(c, revisions, err)
+}
+
+// GetCommentRevisions lists the earlier versions of a comment, oldest first.
+func (h *RevisionHandler) GetCommentRevisions(c *gin.Context) {
+	commentID, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+		return
+	}
+	viewerID, _ := c.Get("user_id")
+	revisions, err := h.uc.GetCommentRevisions(commentID, viewerID.(uuid.UUID))
+	respondRevisions(c, revisions, err)
+}
+
+func respondRevisions(c *gin.Context, revisions []*model.Revision, err error) {
+	switch {
+	case errors.Is(err, service.ErrThreadNotFound), errors.Is(err, service.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"revisions": revisions})
+	}
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"WebMessanger/internal/usecase"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+type TagHandler struct {
+	uc usecase.TagUsecase
+}
+
+func NewTagHandler(uc usecase.TagUsecase) *TagHandler {
+	return &TagHandler{uc: uc}
+}
+func (h *TagHandler) GetThreads(c *gin.Context) {
+	tag := c.Param("tag")
+	viewerID, _ := c.Get("user_id")
+	limit := queryInt(c, "limit", 0)
+	offset := queryInt(c, "offset", 0)
+	threads, err := h.uc.GetThreadsByTag(tag, viewerID.(uuid.UUID), limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tag": tag, "threads": threads, "next_offset": offset + len(threads)})
+}
+func (h *TagHandler) Autocomplete(c *gin.Context) {
+	tags, err := h.uc.Autocomplete(c.Query("q"), queryInt(c, "limit", 10))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tags": tags})
+}
@@ -4,9 +4,11 @@ import (
 	"WebMessanger/internal/app/service"
 	"WebMessanger/internal/model"
 	"WebMessanger/internal/usecase"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"net/http"
+	"time"
 )
 
 type ThreadHandler struct {
@@ -29,8 +31,24 @@ func (h *ThreadHandler) Create(c *gin.Context) {
 	}
 	thread.UserID = userID.(uuid.UUID)
 	created, err := h.uc.Create(&thread)
+	if respondValidationError(c, err) {
+		return
+	}
+	if errors.Is(err, service.ErrThreadNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "quoted thread not found"})
+		return
+	}
+	if errors.Is(err, service.ErrCommunityNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrThreadNotPublic) || errors.Is(err, service.ErrNotCommunityMember) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 	c.JSON(http.StatusCreated, created)
 }
@@ -44,22 +62,77 @@ func (h *ThreadHandler) Update(c *gin.Context) {
 	}
 
 	var body struct {
-		Content string `json:"content"`
+		Title          *string              `json:"title"`
+		Content        *string              `json:"content"`
+		Visibility     *string              `json:"visibility"`
+		ReplyPolicy    *string              `json:"reply_policy"`
+		Attachments    *[]*model.Attachment `json:"attachments"`
+		Sensitive      *bool                `json:"sensitive"`
+		ContentWarning *string              `json:"content_warning"`
+		CategoryID     *string              `json:"category_id"`
 	}
-	if err := c.ShouldBindJSON(&body); err != nil || body.Content == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or empty content"})
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	existing, err := h.uc.GetThreadById(id)
+	viewerID, _ := c.Get("user_id")
+	existing, err := h.uc.GetThreadById(id, viewerID.(uuid.UUID))
+	if errors.Is(err, service.ErrThreadNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "thread not found"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if existing.UserID != viewerID.(uuid.UUID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": service.ErrNotThreadOwner.Error()})
 		return
 	}
 
-	existing.Content = body.Content
+	if body.Content != nil {
+		existing.Content = *body.Content
+	}
+	if body.Title != nil {
+		existing.Title = *body.Title
+	}
+	if body.CategoryID != nil {
+		existing.CategoryID = nil
+		if *body.CategoryID != "" {
+			categoryID, err := uuid.Parse(*body.CategoryID)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category id"})
+				return
+			}
+			existing.CategoryID = &categoryID
+		}
+	}
+	if body.Visibility != nil {
+		existing.Visibility = *body.Visibility
+	}
+	if body.ReplyPolicy != nil {
+		existing.ReplyPolicy = *body.ReplyPolicy
+	}
+	if body.Attachments != nil {
+		existing.Attachments = *body.Attachments
+		existing.MediaURL = ""
+	}
+	if body.Sensitive != nil {
+		existing.Sensitive = *body.Sensitive
+	}
+	if body.ContentWarning != nil {
+		existing.ContentWarning = *body.ContentWarning
+	}
 
 	updated, err := h.uc.Update(existing)
+	if respondValidationError(c, err) {
+		return
+	}
+	if errors.Is(err, service.ErrSensitiveLocked) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -75,14 +148,53 @@ func (h *ThreadHandler) Delete(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	if err := h.uc.Delete(id); err != nil {
+	userID, _ := c.Get("user_id")
+	err = h.uc.Delete(id, userID.(uuid.UUID))
+	switch {
+	case errors.Is(err, service.ErrThreadNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrNotThreadOwner):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, gin.H{"message": "Thread has been deleted"})
+	}
+}
+
+// GetDeleted lists the caller's recently deleted threads.
+func (h *ThreadHandler) GetDeleted(c *gin.Context) {
+	userID, _ := c.Get("user_id")
+	threads, err := h.uc.GetDeleted(userID.(uuid.UUID))
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Thread has been deleted"})
+	c.JSON(http.StatusOK, threads)
+}
+
+// Restore undeletes one of the caller's recently deleted threads.
+func (h *ThreadHandler) Restore(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("thread_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	userID, _ := c.Get("user_id")
+	thread, err := h.uc.Restore(id, userID.(uuid.UUID))
+	if errors.Is(err, service.ErrThreadNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, thread)
 }
 func (h *ThreadHandler) GetAll(c *gin.Context) {
-	threads, err := h.uc.GetAllThreads()
+	viewerID, _ := c.Get("user_id")
+	threads, err := h.uc.GetAllThreads(viewerID.(uuid.UUID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -96,7 +208,12 @@ func (h *ThreadHandler) GetById(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	thread, err := h.uc.GetThreadById(id)
+	viewerID, _ := c.Get("user_id")
+	thread, err := h.uc.GetThreadById(id, viewerID.(uuid.UUID))
+	if errors.Is(err, service.ErrThreadNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -110,10 +227,136 @@ func (h *ThreadHandler) GetByUser(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	threads, err := h.uc.GetByUser(id)
+	viewerID, _ := c.Get("user_id")
+	threads, err := h.uc.GetByUser(id, viewerID.(uuid.UUID))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	c.JSON(http.StatusOK, threads)
 }
+func (h *ThreadHandler) Quote(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	var thread model.Thread
+	if err := c.ShouldBindJSON(&thread); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authorized"})
+		return
+	}
+	thread.UserID = userID.(uuid.UUID)
+	thread.QuoteOfID = &id
+	created, err := h.uc.Create(&thread)
+	if respondValidationError(c, err) {
+		return
+	}
+	if errors.Is(err, service.ErrThreadNotFound) || errors.Is(err, service.ErrCommunityNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrThreadNotPublic) || errors.Is(err, service.ErrNotCommunityMember) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusCreated, created)
+}
+func (h *ThreadHandler) Repost(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authorized"})
+		return
+	}
+	err = h.uc.Repost(id, userID.(uuid.UUID))
+	switch {
+	case errors.Is(err, service.ErrThreadNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrRepostOwnThread):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrThreadNotPublic):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusCreated, gin.H{"message": "Thread has been reposted"})
+	}
+}
+func (h *ThreadHandler) Unrepost(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authorized"})
+		return
+	}
+	if err := h.uc.Unrepost(id, userID.(uuid.UUID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Repost has been removed"})
+}
+func (h *ThreadHandler) GetDrafts(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authorized"})
+		return
+	}
+	threads, err := h.uc.GetDrafts(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, threads)
+}
+func (h *ThreadHandler) Publish(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid thread id"})
+		return
+	}
+	var body struct {
+		PublishAt *time.Time `json:"publish_at"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "not authorized"})
+		return
+	}
+	thread, err := h.uc.Publish(id, userID.(uuid.UUID), body.PublishAt)
+	switch {
+	case errors.Is(err, service.ErrThreadNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrAlreadyPublished):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidPublishTime):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusOK, thread)
+	}
+}
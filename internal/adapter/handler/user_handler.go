@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"WebMessanger/internal/model"
 	"WebMessanger/internal/usecase"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -28,7 +29,7 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"user_id": user.ID, "name": user.Name, "email": user.Email, "avatar_url": user.AvatarURL,
-		"bio": user.Bio, "location": user.Location, "social_links": user.SocialLinks, "created_at": user.CreatedAt})
+		"avatar_variants": user.AvatarVariants, "bio": user.Bio, "location": user.Location, "social_links": user.SocialLinks, "created_at": user.CreatedAt})
 }
 
 func (h *UserHandler) GetMe(c *gin.Context) {
@@ -45,14 +46,17 @@ func (h *UserHandler) GetMe(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"user_id":      user.ID,
-		"name":         user.Name,
-		"email":        user.Email,
-		"avatar_url":   user.AvatarURL,
-		"bio":          user.Bio,
-		"location":     user.Location,
-		"social_links": user.SocialLinks,
-		"created_at":   user.CreatedAt,
+		"user_id":         user.ID,
+		"name":            user.Name,
+		"email":           user.Email,
+		"avatar_url":      user.AvatarURL,
+		"avatar_variants": user.AvatarVariants,
+		"bio":             user.Bio,
+		"location":        user.Location,
+		"social_links":    user.SocialLinks,
+		"created_at":      user.CreatedAt,
+		"sensitive_media": user.SensitiveMedia,
+		"role":            user.Role,
 	})
 }
 
@@ -69,10 +73,6 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	if val, ok := body["name"].(string); ok && len(val) < 3 {
-		c.JSON(400, gin.H{"error": "Name too short"})
-		return
-	}
 	if val, ok := body["name"]; ok {
 		currentUser.Name = val.(string)
 	}
@@ -88,7 +88,18 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	if val, ok := body["avatar_url"].(string); ok && val != "" {
 		currentUser.AvatarURL = val
 	}
+	if val, ok := body["sensitive_media"]; ok {
+		pref, _ := val.(string)
+		if pref != model.SensitiveMediaShow && pref != model.SensitiveMediaBlur && pref != model.SensitiveMediaHide {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sensitive_media must be show, blur or hide"})
+			return
+		}
+		currentUser.SensitiveMedia = pref
+	}
 	if err := h.uc.UpdateProfile(currentUser); err != nil {
+		if respondValidationError(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -0,0 +1,19 @@
+package handler
+
+import (
+	"WebMessanger/internal/app/service"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// respondValidationError writes a 400 listing the invalid fields when err is
+// a *service.ValidationError, and reports whether it did.
+func respondValidationError(c *gin.Context, err error) bool {
+	var verr *service.ValidationError
+	if !errors.As(err, &verr) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed", "fields": verr.Fields})
+	return true
+}
@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"WebMessanger/internal/model"
+	"database/sql"
+	"github.com/google/uuid"
+)
+
+type auditRepo struct {
+	db *sql.DB
+}
+
+func NewAuditRepo(db *sql.DB) *auditRepo {
+	return &auditRepo{db: db}
+}
+
+// recordAudit writes entry inside tx, so the audit log only ever lists
+// actions whose change was committed with it.
+func recordAudit(tx *sql.Tx, entry *model.AuditEntry) error {
+	_, err := tx.Exec(`
+		INSERT INTO audit_log (id, actor_id, action, target_type, target_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, entry.ID, entry.ActorID, entry.Action, entry.TargetType, entry.TargetID, entry.CreatedAt)
+	return err
+}
+
+// GetByTarget returns the entries for one target, newest first.
+func (r *auditRepo) GetByTarget(targetType string, targetID uuid.UUID) ([]*model.AuditEntry, error) {
+	rows, err := r.db.Query(`
+		SELECT a.id, a.actor_id, COALESCE(u.name, ''), a.action, a.target_type, a.target_id, a.created_at
+		FROM audit_log a
+		LEFT JOIN users u ON u.id = a.actor_id
+		WHERE a.target_type = $1 AND a.target_id = $2
+		ORDER BY a.created_at DESC
+	`, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	entries := make([]*model.AuditEntry, 0)
+	for rows.Next() {
+		var e model.AuditEntry
+		var actorID uuid.NullUUID
+		if err := rows.Scan(&e.ID, &actorID, &e.ActorName, &e.Action, &e.TargetType, &e.TargetID, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if actorID.Valid {
+			e.ActorID = &actorID.UUID
+		}
+		entries = append(entries, &e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"WebMessanger/internal/model"
+	"database/sql"
+	"github.com/google/uuid"
+)
+
+type blockRepo struct {
+	db *sql.DB
+}
+
+func NewBlockRepo(db *sql.DB) *blockRepo {
+	return &blockRepo{db: db}
+}
+
+func (r *blockRepo) Block(blockerID, blockedID uuid.UUID) error {
+	_, err := r.db.Exec(`
+		INSERT INTO blocks (blocker_id, blocked_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, blockerID, blockedID)
+	return err
+}
+
+func (r *blockRepo) Unblock(blockerID, blockedID uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM blocks WHERE blocker_id = $1 AND blocked_id = $2`, blockerID, blockedID)
+	return err
+}
+
+func (r *blockRepo) IsBlocked(userID, otherID uuid.UUID) (bool, error) {
+	var blocked bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM blocks
+			WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1)
+		)
+	`, userID, otherID).Scan(&blocked)
+	return blocked, err
+}
+
+func (r *blockRepo) GetBlocked(userID uuid.UUID) ([]*model.PublicUser, error) {
+	rows, err := r.db.Query(`
+		SELECT u.id, u.name, u.avatar_url
+		FROM blocks b
+		JOIN users u ON u.id = b.blocked_id
+		WHERE b.blocker_id = $1
+		ORDER BY b.created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	users := make([]*model.PublicUser, 0)
+	for rows.Next() {
+		var u model.PublicUser
+		if err := rows.Scan(&u.ID, &u.Name, &u.AvatarURL); err != nil {
+			return nil, err
+		}
+		users = append(users, &u)
+	}
+	return users, rows.Err()
+}
@@ -0,0 +1,97 @@
+package postgres
+
+import (
+	"WebMessanger/internal/model"
+	"database/sql"
+	"github.com/google/uuid"
+	"strconv"
+)
+
+type bookmarkRepo struct {
+	db *sql.DB
+}
+
+func NewBookmarkRepo(db *sql.DB) *bookmarkRepo {
+	return &bookmarkRepo{db: db}
+}
+
+// Add saves a thread for the user, moving it to the new folder if it was
+// already bookmarked.
+func (r *bookmarkRepo) Add(b *model.Bookmark) error {
+	return r.db.QueryRow(`
+		INSERT INTO bookmarks (id, user_id, thread_id, folder, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, thread_id) DO UPDATE SET folder = EXCLUDED.folder
+		RETURNING id, created_at
+	`, b.ID, b.UserID, b.ThreadID, b.Folder, b.CreatedAt).Scan(&b.ID, &b.CreatedAt)
+}
+
+func (r *bookmarkRepo) Remove(threadID, userID uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM bookmarks WHERE thread_id = $1 AND user_id = $2`, threadID, userID)
+	return err
+}
+
+func (r *bookmarkRepo) List(userID uuid.UUID, folder *string, after *model.BookmarkCursor, limit int) ([]*model.Bookmark, error) {
+	query := `
+		SELECT ` + threadColumns + `, b.id, b.user_id, b.thread_id, b.folder, b.created_at
+		FROM bookmarks b
+		JOIN threads t ON t.id = b.thread_id
+		JOIN users u ON t.user_id = u.id
+		WHERE b.user_id = $1 AND ` + visibleToViewer
+	args := []interface{}{userID}
+	if folder != nil {
+		args = append(args, *folder)
+		query += ` AND b.folder = $2`
+	}
+	if after != nil {
+		args = append(args, after.CreatedAt, after.ID)
+		query += ` AND (b.created_at, b.id) < ($` + strconv.Itoa(len(args)-1) + `, $` + strconv.Itoa(len(args)) + `)`
+	}
+	args = append(args, limit)
+	query += `
+		ORDER BY b.created_at DESC, b.id DESC LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	bookmarks := make([]*model.Bookmark, 0)
+	for rows.Next() {
+		var b model.Bookmark
+		t, err := scanThread(rows, &b.ID, &b.UserID, &b.ThreadID, &b.Folder, &b.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		b.Thread = t
+		bookmarks = append(bookmarks, &b)
+	}
+	return bookmarks, nil
+}
+
+// GetFolders counts only the bookmarks List would show, so a folder's count
+// matches its listing.
+func (r *bookmarkRepo) GetFolders(userID uuid.UUID) ([]*model.BookmarkFolder, error) {
+	rows, err := r.db.Query(`
+		SELECT b.folder, COUNT(*)
+		FROM bookmarks b
+		JOIN threads t ON t.id = b.thread_id
+		JOIN users u ON t.user_id = u.id
+		WHERE b.user_id = $1 AND `+visibleToViewer+`
+		GROUP BY b.folder
+		ORDER BY b.folder ASC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	folders := make([]*model.BookmarkFolder, 0)
+	for rows.Next() {
+		var f model.BookmarkFolder
+		if err := rows.Scan(&f.Name, &f.Count); err != nil {
+			return nil, err
+		}
+		folders = append(folders, &f)
+	}
+	return folders, nil
+}
@@ -0,0 +1,76 @@
+package postgres
+
+import (
+	"WebMessanger/internal/model"
+	"database/sql"
+	"github.com/google/uuid"
+)
+
+// categoryColumns expects categories aliased as c. Only public, published
+// threads that have not expired are counted.
+const categoryColumns = `
+	c.id, c.slug, c.name, c.description, c.position, c.created_at,
+	(SELECT COUNT(*) FROM threads t WHERE t.category_id = c.id AND t.status = 'published'
+		AND t.visibility = 'public' AND ` + notExpired + ` AND ` + notDeleted + `)`
+
+type categoryRepo struct {
+	db *sql.DB
+}
+
+func NewCategoryRepo(db *sql.DB) *categoryRepo {
+	return &categoryRepo{db: db}
+}
+
+func (r *categoryRepo) List() ([]*model.Category, error) {
+	rows, err := r.db.Query(`SELECT ` + categoryColumns + ` FROM categories c ORDER BY c.position, c.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	categories := make([]*model.Category, 0)
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+func (r *categoryRepo) GetByID(id uuid.UUID) (*model.Category, error) {
+	return scanCategory(r.db.QueryRow(`SELECT `+categoryColumns+` FROM categories c WHERE c.id = $1`, id))
+}
+
+func (r *categoryRepo) GetBySlug(slug string) (*model.Category, error) {
+	return scanCategory(r.db.QueryRow(`SELECT `+categoryColumns+` FROM categories c WHERE c.slug = $1`, slug))
+}
+
+func (r *categoryRepo) Create(category *model.Category) error {
+	_, err := r.db.Exec(`
+		INSERT INTO categories (id, slug, name, description, position, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, category.ID, category.Slug, category.Name, category.Description, category.Position, category.CreatedAt)
+	return err
+}
+
+func (r *categoryRepo) Update(category *model.Category) error {
+	_, err := r.db.Exec(`
+		UPDATE categories SET slug = $1, name = $2, description = $3, position = $4 WHERE id = $5
+	`, category.Slug, category.Name, category.Description, category.Position, category.ID)
+	return err
+}
+
+// Delete removes the category; its threads stay, uncategorized.
+func (r *categoryRepo) Delete(id uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM categories WHERE id = $1`, id)
+	return err
+}
+
+func scanCategory(row rowScanner) (*model.Category, error) {
+	var c model.Category
+	if err := row.Scan(&c.ID, &c.Slug, &c.Name, &c.Description, &c.Position, &c.CreatedAt, &c.ThreadCount); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -4,8 +4,23 @@ import (
 	"WebMessanger/internal/model"
 	"database/sql"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"html"
+	"time"
 )
 
+// commentDeleted is true for comments that were deleted, whether they can
+// still be restored or were already scrubbed by the purge job.
+const commentDeleted = `(c.is_deleted OR c.deleted_at IS NOT NULL)`
+
+// commentContentColumns selects the content, HTML and mentions of comment c,
+// replacing them with a placeholder when it is deleted.
+var commentContentColumns = `
+	CASE WHEN ` + commentDeleted + ` THEN ` + pq.QuoteLiteral(model.DeletedCommentContent) + ` ELSE c.content END,
+	CASE WHEN ` + commentDeleted + ` THEN ` + pq.QuoteLiteral(html.EscapeString(model.DeletedCommentContent)) + ` ELSE COALESCE(c.content_html, '') END,
+	` + commentDeleted + `, c.created_at, c.edited_at,
+	CASE WHEN ` + commentDeleted + ` THEN '[]' ELSE ` + mentionsColumn(model.MentionSourceComment, "c.id") + ` END`
+
 type commentRepo struct {
 	db *sql.DB
 }
@@ -14,19 +29,41 @@ func NewCommentRepo(db *sql.DB) *commentRepo {
 	return &commentRepo{db: db}
 }
 func (r *commentRepo) Create(cmt *model.Comment) (*model.Comment, error) {
-	_, err := r.db.Exec(`INSERT INTO comments (id, thread_id, user_id, user_name, content, created_at) VALUES ($1, $2, $3, $4, $5, $6)`, cmt.ID, cmt.ThreadID, cmt.UserID, cmt.UserName, cmt.Content, cmt.CreatedAt)
+	_, err := r.db.Exec(`INSERT INTO comments (id, thread_id, parent_id, user_id, user_name, content, content_html, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`, cmt.ID, cmt.ThreadID, cmt.ParentID, cmt.UserID, cmt.UserName, cmt.Content, cmt.ContentHTML, cmt.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 	return cmt, nil
 }
-func (r *commentRepo) GetByThread(threadID uuid.UUID) ([]*model.Comment, error) {
+func (r *commentRepo) GetByID(id uuid.UUID) (*model.Comment, error) {
+	var c model.Comment
+	var parentID uuid.NullUUID
+	var mentions []byte
+	err := r.db.QueryRow(`
+		SELECT c.id, c.thread_id, c.parent_id, c.user_id, c.user_name, `+commentContentColumns+`
+		FROM comments c
+		WHERE c.id = $1
+	`, id).Scan(&c.ID, &c.ThreadID, &parentID, &c.UserID, &c.UserName, &c.Content, &c.ContentHTML, &c.IsDeleted, &c.CreatedAt, &c.EditedAt, &mentions)
+	if err != nil {
+		return nil, err
+	}
+	if parentID.Valid {
+		c.ParentID = &parentID.UUID
+	}
+	if c.Mentions, err = decodeMentions(mentions); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+func (r *commentRepo) GetByThread(threadID, viewerID uuid.UUID) ([]*model.Comment, error) {
 	rows, err := r.db.Query(`
-		SELECT id, thread_id, user_id, user_name, content, created_at
-		FROM comments
-		WHERE thread_id = $1
-		ORDER BY created_at ASC
-	`, threadID)
+		SELECT c.id, c.thread_id, c.parent_id, c.user_id, c.user_name, `+commentContentColumns+`,
+		       (SELECT COUNT(*) FROM likes l WHERE l.target_type = 'comment' AND l.target_id = c.id),
+		       COALESCE((SELECT l.reaction FROM likes l WHERE l.target_type = 'comment' AND l.target_id = c.id AND l.user_id = $2), '')
+		FROM comments c
+		WHERE c.thread_id = $1
+		ORDER BY c.created_at ASC
+	`, threadID, viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -35,15 +72,183 @@ func (r *commentRepo) GetByThread(threadID uuid.UUID) ([]*model.Comment, error)
 	var comments []*model.Comment
 	for rows.Next() {
 		var c model.Comment
-		if err := rows.Scan(&c.ID, &c.ThreadID, &c.UserID, &c.UserName, &c.Content, &c.CreatedAt); err != nil {
+		var parentID uuid.NullUUID
+		var mentions []byte
+		if err := rows.Scan(&c.ID, &c.ThreadID, &parentID, &c.UserID, &c.UserName, &c.Content, &c.ContentHTML, &c.IsDeleted, &c.CreatedAt, &c.EditedAt,
+			&mentions, &c.LikeCount, &c.MyReaction); err != nil {
+			return nil, err
+		}
+		c.LikedByMe = c.MyReaction != ""
+		if c.Mentions, err = decodeMentions(mentions); err != nil {
 			return nil, err
 		}
+		if parentID.Valid {
+			c.ParentID = &parentID.UUID
+		}
 		comments = append(comments, &c)
 	}
 
 	return comments, nil
 }
-func (r *commentRepo) Delete(id uuid.UUID) error {
-	_, err := r.db.Exec(`DELETE FROM comments WHERE id = $1`, id)
+
+// Update replaces the content of a comment. When the text actually changed,
+// the previous version is kept as a revision and edited_at is set.
+func (r *commentRepo) Update(cmt *model.Comment) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`
+		INSERT INTO revisions (id, target_type, target_id, content, created_at)
+		SELECT $1, 'comment', id, content, COALESCE(edited_at, created_at)
+		FROM comments
+		WHERE id = $2 AND content <> $3
+	`, uuid.New(), cmt.ID, cmt.Content); err != nil {
+		return err
+	}
+	if err := tx.QueryRow(`
+		UPDATE comments
+		SET content = $1, content_html = $2,
+		    edited_at = CASE WHEN content <> $1 THEN NOW() ELSE edited_at END
+		WHERE id = $3
+		RETURNING edited_at
+	`, cmt.Content, cmt.ContentHTML, cmt.ID).Scan(&cmt.EditedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Delete soft-deletes a comment, recording who deleted it. Reads show a
+// placeholder for it until PurgeDeleted removes it.
+func (r *commentRepo) Delete(id, deletedBy uuid.UUID) error {
+	_, err := r.db.Exec(`UPDATE comments SET deleted_at = NOW(), deleted_by = $2 WHERE id = $1 AND deleted_at IS NULL`,
+		id, deletedBy)
+	return err
+}
+
+// GetDeletedByUser returns the comments the user deleted themselves since the
+// given time, most recently deleted first, leaving out those whose thread is
+// gone too. Content is returned as it was before the deletion.
+func (r *commentRepo) GetDeletedByUser(userID uuid.UUID, since time.Time) ([]*model.Comment, error) {
+	rows, err := r.db.Query(`
+		SELECT c.id, c.thread_id, c.parent_id, c.user_id, c.user_name, c.content, COALESCE(c.content_html, ''),
+		       c.created_at, c.edited_at, c.deleted_at, `+mentionsColumn(model.MentionSourceComment, "c.id")+`
+		FROM comments c
+		JOIN threads t ON t.id = c.thread_id
+		WHERE c.user_id = $1 AND c.deleted_by = $1 AND c.deleted_at > $2 AND NOT c.is_deleted AND `+notDeleted+`
+		ORDER BY c.deleted_at DESC
+	`, userID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	comments := make([]*model.Comment, 0)
+	for rows.Next() {
+		c := model.Comment{IsDeleted: true}
+		var parentID uuid.NullUUID
+		var mentions []byte
+		if err := rows.Scan(&c.ID, &c.ThreadID, &parentID, &c.UserID, &c.UserName, &c.Content, &c.ContentHTML,
+			&c.CreatedAt, &c.EditedAt, &c.DeletedAt, &mentions); err != nil {
+			return nil, err
+		}
+		if parentID.Valid {
+			c.ParentID = &parentID.UUID
+		}
+		if c.Mentions, err = decodeMentions(mentions); err != nil {
+			return nil, err
+		}
+		comments = append(comments, &c)
+	}
+	return comments, rows.Err()
+}
+
+// Restore undeletes a comment the user deleted themselves since the given
+// time, and reports whether there was such a comment.
+func (r *commentRepo) Restore(id, userID uuid.UUID, since time.Time) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE comments
+		SET deleted_at = NULL, deleted_by = NULL
+		WHERE id = $1 AND user_id = $2 AND deleted_by = $2 AND deleted_at > $3 AND NOT is_deleted
+	`, id, userID, since)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// PurgeDeleted permanently removes up to limit comments that were deleted
+// before the given time and returns how many it handled. Comments that still
+// have replies keep their row so the thread stays intact, but their content
+// is scrubbed. Rows are claimed with SKIP LOCKED.
+func (r *commentRepo) PurgeDeleted(before time.Time, limit int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	leaves, err := selectIDs(tx, `
+		SELECT id
+		FROM comments c
+		WHERE deleted_at < $1 AND NOT EXISTS (SELECT 1 FROM comments r WHERE r.parent_id = c.id)
+		ORDER BY deleted_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`DELETE FROM likes WHERE target_type = 'comment' AND target_id = ANY($1::uuid[])`, pq.Array(leaves)); err != nil {
+		return 0, err
+	}
+	parents, err := selectIDs(tx, `
+		SELECT id
+		FROM comments c
+		WHERE deleted_at < $1 AND NOT is_deleted AND EXISTS (SELECT 1 FROM comments r WHERE r.parent_id = c.id)
+		ORDER BY deleted_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, before, limit)
+	if err != nil {
+		return 0, err
+	}
+	ids := pq.Array(append(leaves, parents...))
+	if _, err := tx.Exec(`DELETE FROM mentions WHERE source_type = 'comment' AND source_id = ANY($1::uuid[])`, ids); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`DELETE FROM revisions WHERE target_type = 'comment' AND target_id = ANY($1::uuid[])`, ids); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`DELETE FROM comments WHERE id = ANY($1::uuid[])`, pq.Array(leaves)); err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(`UPDATE comments SET is_deleted = TRUE, content = $1, content_html = $2 WHERE id = ANY($3::uuid[])`,
+		model.DeletedCommentContent, html.EscapeString(model.DeletedCommentContent), pq.Array(parents)); err != nil {
+		return 0, err
+	}
+	return len(leaves) + len(parents), tx.Commit()
+}
+
+// GetUnrendered returns up to limit comments whose content_html has not been
+// filled in yet. Only ID and Content are set.
+func (r *commentRepo) GetUnrendered(limit int) ([]*model.Comment, error) {
+	rows, err := r.db.Query(`SELECT id, content FROM comments WHERE content_html IS NULL LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var comments []*model.Comment
+	for rows.Next() {
+		var c model.Comment
+		if err := rows.Scan(&c.ID, &c.Content); err != nil {
+			return nil, err
+		}
+		comments = append(comments, &c)
+	}
+	return comments, rows.Err()
+}
+func (r *commentRepo) SetContentHTML(id uuid.UUID, contentHTML string) error {
+	_, err := r.db.Exec(`UPDATE comments SET content_html = $1 WHERE id = $2`, contentHTML, id)
 	return err
 }
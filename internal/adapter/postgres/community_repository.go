@@ -0,0 +1,227 @@
+package postgres
+
+import (
+	"WebMessanger/internal/model"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"strings"
+)
+
+// communityColumns is the select list of community reads; it expects
+// communities aliased as c, the owner as u, and the viewer bound as $1.
+const communityColumns = `
+	c.id, c.name, c.description, c.owner_id, u.name, c.created_at,
+	(SELECT COUNT(*) FROM community_members m WHERE m.community_id = c.id) AS member_count,
+	COALESCE((SELECT m.role FROM community_members m WHERE m.community_id = c.id AND m.user_id = $1), '')`
+
+type communityRepo struct {
+	db *sql.DB
+}
+
+func NewCommunityRepo(db *sql.DB) *communityRepo {
+	return &communityRepo{db: db}
+}
+
+// Create stores the community and makes its owner the first member.
+func (r *communityRepo) Create(community *model.Community) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`
+		INSERT INTO communities (id, name, description, owner_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, community.ID, community.Name, community.Description, community.OwnerID, community.CreatedAt); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO community_members (community_id, user_id, role, joined_at)
+		VALUES ($1, $2, 'owner', $3)
+	`, community.ID, community.OwnerID, community.CreatedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *communityRepo) GetByID(id, viewerID uuid.UUID) (*model.Community, error) {
+	return scanCommunity(r.db.QueryRow(`
+		SELECT `+communityColumns+`
+		FROM communities c
+		JOIN users u ON u.id = c.owner_id
+		WHERE c.id = $2
+	`, viewerID, id))
+}
+
+// GetByName looks a community up by name, ignoring case.
+func (r *communityRepo) GetByName(name string) (*model.Community, error) {
+	return scanCommunity(r.db.QueryRow(`
+		SELECT `+communityColumns+`
+		FROM communities c
+		JOIN users u ON u.id = c.owner_id
+		WHERE LOWER(c.name) = LOWER($2)
+	`, uuid.Nil, name))
+}
+
+// List returns communities whose name contains query, largest first.
+func (r *communityRepo) List(query string, viewerID uuid.UUID, limit, offset int) ([]*model.Community, error) {
+	pattern := "%" + strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(query) + "%"
+	rows, err := r.db.Query(`
+		SELECT `+communityColumns+`
+		FROM communities c
+		JOIN users u ON u.id = c.owner_id
+		WHERE c.name ILIKE $2
+		ORDER BY member_count DESC, c.name ASC
+		LIMIT $3 OFFSET $4
+	`, viewerID, pattern, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	communities := make([]*model.Community, 0)
+	for rows.Next() {
+		community, err := scanCommunity(rows)
+		if err != nil {
+			return nil, err
+		}
+		communities = append(communities, community)
+	}
+	return communities, rows.Err()
+}
+
+func (r *communityRepo) Update(community *model.Community) error {
+	_, err := r.db.Exec(`UPDATE communities SET name = $1, description = $2 WHERE id = $3`,
+		community.Name, community.Description, community.ID)
+	return err
+}
+
+// Delete removes the community and soft-deletes every thread posted into it,
+// recording deletedBy, and drops their pins; the purge job removes those
+// threads later.
+func (r *communityRepo) Delete(id, deletedBy uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`
+		WITH deleted AS (
+			UPDATE threads SET deleted_at = NOW(), deleted_by = $2
+			WHERE community_id = $1 AND deleted_at IS NULL
+			RETURNING id
+		)
+		DELETE FROM pinned_threads WHERE thread_id IN (SELECT id FROM deleted)
+	`, id, deletedBy); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM communities WHERE id = $1`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetRole returns the user's role in the community, or "" when they are not
+// a member.
+func (r *communityRepo) GetRole(communityID, userID uuid.UUID) (string, error) {
+	var role string
+	err := r.db.QueryRow(`SELECT role FROM community_members WHERE community_id = $1 AND user_id = $2`,
+		communityID, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return role, err
+}
+
+func (r *communityRepo) AddMember(communityID, userID uuid.UUID, role string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO community_members (community_id, user_id, role) VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`, communityID, userID, role)
+	return err
+}
+
+func (r *communityRepo) RemoveMember(communityID, userID uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM community_members WHERE community_id = $1 AND user_id = $2`, communityID, userID)
+	return err
+}
+
+// BanMember removes the user from the community and records the ban in one
+// transaction.
+func (r *communityRepo) BanMember(communityID, userID, bannedBy uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM community_members WHERE community_id = $1 AND user_id = $2`, communityID, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO community_bans (community_id, user_id, banned_by) VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`, communityID, userID, bannedBy); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Unban lifts a ban and reports whether there was one.
+func (r *communityRepo) Unban(communityID, userID uuid.UUID) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM community_bans WHERE community_id = $1 AND user_id = $2`, communityID, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *communityRepo) IsBanned(communityID, userID uuid.UUID) (bool, error) {
+	var banned bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM community_bans WHERE community_id = $1 AND user_id = $2)
+	`, communityID, userID).Scan(&banned)
+	return banned, err
+}
+
+func (r *communityRepo) SetRole(communityID, userID uuid.UUID, role string) error {
+	_, err := r.db.Exec(`UPDATE community_members SET role = $1 WHERE community_id = $2 AND user_id = $3`,
+		role, communityID, userID)
+	return err
+}
+
+// GetMembers lists members with the owner first, then moderators, then
+// everyone else by join date.
+func (r *communityRepo) GetMembers(communityID uuid.UUID, limit, offset int) ([]*model.CommunityMember, error) {
+	rows, err := r.db.Query(`
+		SELECT u.id, u.name, u.avatar_url, m.role, m.joined_at
+		FROM community_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.community_id = $1
+		ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'moderator' THEN 1 ELSE 2 END, m.joined_at ASC
+		LIMIT $2 OFFSET $3
+	`, communityID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	members := make([]*model.CommunityMember, 0)
+	for rows.Next() {
+		var m model.CommunityMember
+		if err := rows.Scan(&m.UserID, &m.UserName, &m.AvatarURL, &m.Role, &m.JoinedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, &m)
+	}
+	return members, rows.Err()
+}
+
+func scanCommunity(row rowScanner) (*model.Community, error) {
+	var c model.Community
+	if err := row.Scan(&c.ID, &c.Name, &c.Description, &c.OwnerID, &c.OwnerName, &c.CreatedAt,
+		&c.MemberCount, &c.MyRole); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"WebMessanger/internal/model"
+	"database/sql"
+	"github.com/google/uuid"
+)
+
+type followRepo struct {
+	db *sql.DB
+}
+
+func NewFollowRepo(db *sql.DB) *followRepo {
+	return &followRepo{db: db}
+}
+
+func (r *followRepo) Follow(followerID, followeeID uuid.UUID) error {
+	_, err := r.db.Exec(`
+		INSERT INTO follows (follower_id, followee_id) VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`, followerID, followeeID)
+	return err
+}
+
+func (r *followRepo) Unfollow(followerID, followeeID uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2`, followerID, followeeID)
+	return err
+}
+
+func (r *followRepo) IsFollowing(followerID, followeeID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM follows WHERE follower_id = $1 AND followee_id = $2)`,
+		followerID, followeeID).Scan(&exists)
+	return exists, err
+}
+
+func (r *followRepo) GetFollowers(userID uuid.UUID) ([]*model.PublicUser, error) {
+	return r.listUsers(`
+		SELECT u.id, u.name, u.avatar_url
+		FROM follows f
+		JOIN users u ON u.id = f.follower_id
+		WHERE f.followee_id = $1
+		ORDER BY f.created_at DESC
+	`, userID)
+}
+
+func (r *followRepo) GetFollowing(userID uuid.UUID) ([]*model.PublicUser, error) {
+	return r.listUsers(`
+		SELECT u.id, u.name, u.avatar_url
+		FROM follows f
+		JOIN users u ON u.id = f.followee_id
+		WHERE f.follower_id = $1
+		ORDER BY f.created_at DESC
+	`, userID)
+}
+
+func (r *followRepo) listUsers(query string, userID uuid.UUID) ([]*model.PublicUser, error) {
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	users := make([]*model.PublicUser, 0)
+	for rows.Next() {
+		var u model.PublicUser
+		if err := rows.Scan(&u.ID, &u.Name, &u.AvatarURL); err != nil {
+			return nil, err
+		}
+		users = append(users, &u)
+	}
+	return users, nil
+}
@@ -3,6 +3,7 @@ package postgres
 import (
 	"WebMessanger/internal/model"
 	"database/sql"
+	"errors"
 	"github.com/google/uuid"
 )
 
@@ -13,51 +14,108 @@ type likeRepo struct {
 func NewLikeRepo(db *sql.DB) *likeRepo {
 	return &likeRepo{db: db}
 }
+
+// AddLike records the user's reaction unless they already reacted to the
+// target, in which case like is filled in from the reaction they left.
 func (r *likeRepo) AddLike(like *model.Like) error {
-	_, err := r.db.Exec(`
-		INSERT INTO likes (id, thread_id, user_id, user_name, created_at)
-		VALUES ($1, $2, $3, $4, $5)
-		ON CONFLICT (thread_id, user_id) DO NOTHING
-	`, like.ID, like.ThreadID, like.UserID, like.UserName, like.CreatedAt)
+	threadID := uuid.NullUUID{UUID: like.ThreadID, Valid: like.ThreadID != uuid.Nil}
+	err := r.db.QueryRow(`
+		INSERT INTO likes (id, target_type, target_id, thread_id, user_id, user_name, reaction, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (target_type, target_id, user_id) DO NOTHING
+		RETURNING id, created_at
+	`, like.ID, like.TargetType, like.TargetID, threadID, like.UserID, like.UserName, like.Reaction, like.CreatedAt).
+		Scan(&like.ID, &like.CreatedAt)
+	if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return r.db.QueryRow(`
+		SELECT id, reaction, created_at FROM likes WHERE target_type = $1 AND target_id = $2 AND user_id = $3
+	`, like.TargetType, like.TargetID, like.UserID).Scan(&like.ID, &like.Reaction, &like.CreatedAt)
+}
 
-	return err
+// SetReaction records the user's reaction, replacing any earlier reaction
+// they left on the same target.
+func (r *likeRepo) SetReaction(like *model.Like) error {
+	threadID := uuid.NullUUID{UUID: like.ThreadID, Valid: like.ThreadID != uuid.Nil}
+	return r.db.QueryRow(`
+		INSERT INTO likes (id, target_type, target_id, thread_id, user_id, user_name, reaction, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (target_type, target_id, user_id) DO UPDATE SET reaction = EXCLUDED.reaction
+		RETURNING id, created_at
+	`, like.ID, like.TargetType, like.TargetID, threadID, like.UserID, like.UserName, like.Reaction, like.CreatedAt).
+		Scan(&like.ID, &like.CreatedAt)
 }
 
-func (r *likeRepo) RemoveLike(threadID uuid.UUID, userID uuid.UUID) error {
-	_, err := r.db.Exec(`DELETE FROM likes WHERE thread_id = $1 AND user_id = $2`, threadID, userID)
+// RemoveLike removes the user's reaction to the target. A non-empty reaction
+// limits it to that reaction, leaving any other one in place.
+func (r *likeRepo) RemoveLike(targetType string, targetID uuid.UUID, userID uuid.UUID, reaction string) error {
+	_, err := r.db.Exec(`
+		DELETE FROM likes
+		WHERE target_type = $1 AND target_id = $2 AND user_id = $3 AND ($4 = '' OR reaction = $4)
+	`, targetType, targetID, userID, reaction)
 	return err
 }
-func (r *likeRepo) GetLikesByThread(threadID uuid.UUID) ([]*model.Like, error) {
-	rows, err := r.db.Query(`SELECT id, thread_id, user_id, user_name, created_at
+func (r *likeRepo) GetLikesByTarget(targetType string, targetID uuid.UUID) ([]*model.Like, error) {
+	rows, err := r.db.Query(`SELECT id, target_type, target_id, thread_id, user_id, user_name, reaction, created_at
 		FROM likes
-		WHERE thread_id = $1
-		ORDER BY created_at ASC`, threadID)
+		WHERE target_type = $1 AND target_id = $2
+		ORDER BY created_at ASC`, targetType, targetID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var likes []*model.Like
-	for rows.Next() {
-		var l model.Like
-		if err := rows.Scan(&l.ID, &l.ThreadID, &l.UserID, &l.UserName, &l.CreatedAt); err != nil {
-			return nil, err
-		}
-		likes = append(likes, &l)
-	}
-	return likes, nil
+	return scanLikes(rows)
 }
 func (r *likeRepo) GetLikesByUser(userID uuid.UUID) ([]*model.Like, error) {
-	rows, err := r.db.Query(`SELECT id, thread_id, user_id, user_name, created_at FROM likes WHERE user_id = $1 ORDER BY  created_at ASC`, userID)
+	rows, err := r.db.Query(`SELECT id, target_type, target_id, thread_id, user_id, user_name, reaction, created_at FROM likes WHERE user_id = $1 ORDER BY  created_at ASC`, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
+	return scanLikes(rows)
+}
+func (r *likeRepo) CountReactions(targetType string, targetID uuid.UUID) (map[string]int, error) {
+	rows, err := r.db.Query(`
+		SELECT reaction, COUNT(*)
+		FROM likes
+		WHERE target_type = $1 AND target_id = $2
+		GROUP BY reaction
+	`, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := make(map[string]int)
+	for rows.Next() {
+		var reaction string
+		var count int
+		if err := rows.Scan(&reaction, &count); err != nil {
+			return nil, err
+		}
+		counts[reaction] = count
+	}
+	return counts, nil
+}
+func (r *likeRepo) GetUserReaction(targetType string, targetID uuid.UUID, userID uuid.UUID) (string, error) {
+	var reaction string
+	err := r.db.QueryRow(`SELECT reaction FROM likes WHERE target_type = $1 AND target_id = $2 AND user_id = $3`,
+		targetType, targetID, userID).Scan(&reaction)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return reaction, err
+}
+
+func scanLikes(rows *sql.Rows) ([]*model.Like, error) {
 	var likes []*model.Like
 	for rows.Next() {
 		var l model.Like
-		if err := rows.Scan(&l.ID, &l.ThreadID, &l.UserID, &l.UserName, &l.CreatedAt); err != nil {
+		var threadID uuid.NullUUID
+		if err := rows.Scan(&l.ID, &l.TargetType, &l.TargetID, &threadID, &l.UserID, &l.UserName, &l.Reaction, &l.CreatedAt); err != nil {
 			return nil, err
 		}
+		l.ThreadID = threadID.UUID
 		likes = append(likes, &l)
 	}
 	return likes, nil
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"WebMessanger/internal/model"
+	"database/sql"
+	"encoding/json"
+)
+
+// previewColumn selects the cached link preview of thread t as JSON, or NULL
+// while it has not been fetched or the fetch failed.
+const previewColumn = `(
+	SELECT json_build_object(
+		'url', lp.url, 'title', lp.title, 'description', lp.description,
+		'image_url', lp.image_url, 'site_name', lp.site_name, 'fetched_at', lp.fetched_at
+	)
+	FROM link_previews lp
+	WHERE lp.url = t.preview_url AND NOT lp.failed
+)`
+
+type linkPreviewRepo struct {
+	db *sql.DB
+}
+
+func NewLinkPreviewRepo(db *sql.DB) *linkPreviewRepo {
+	return &linkPreviewRepo{db: db}
+}
+
+func (r *linkPreviewRepo) Get(url string) (*model.LinkPreview, error) {
+	var p model.LinkPreview
+	err := r.db.QueryRow(`
+		SELECT url, title, description, image_url, site_name, failed, fetched_at
+		FROM link_previews
+		WHERE url = $1
+	`, url).Scan(&p.URL, &p.Title, &p.Description, &p.ImageURL, &p.SiteName, &p.Failed, &p.FetchedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *linkPreviewRepo) Save(p *model.LinkPreview) error {
+	_, err := r.db.Exec(`
+		INSERT INTO link_previews (url, title, description, image_url, site_name, failed, fetched_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (url) DO UPDATE
+		SET title = EXCLUDED.title, description = EXCLUDED.description, image_url = EXCLUDED.image_url,
+		    site_name = EXCLUDED.site_name, failed = EXCLUDED.failed, fetched_at = EXCLUDED.fetched_at
+	`, p.URL, p.Title, p.Description, p.ImageURL, p.SiteName, p.Failed, p.FetchedAt)
+	return err
+}
+
+func decodePreview(raw []byte) (*model.LinkPreview, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var p model.LinkPreview
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
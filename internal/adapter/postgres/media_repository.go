@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"WebMessanger/internal/model"
+	"database/sql"
+	"encoding/json"
+	"github.com/google/uuid"
+)
+
+// variantsColumn selects the resized variants recorded for the upload whose
+// URL is urlExpr, as a JSON object keyed by variant name.
+func variantsColumn(urlExpr string) string {
+	return `COALESCE((SELECT mu.variants FROM media_uploads mu WHERE mu.url = ` + urlExpr + `), '{}')`
+}
+
+type mediaRepo struct {
+	db *sql.DB
+}
+
+func NewMediaRepo(db *sql.DB) *mediaRepo {
+	return &mediaRepo{db: db}
+}
+
+func (r *mediaRepo) Save(userID uuid.UUID, m *model.Media) error {
+	variants, err := json.Marshal(m.Variants)
+	if err != nil {
+		return err
+	}
+	_, err = r.db.Exec(`
+		INSERT INTO media_uploads (url, user_id, kind, content_type, size, width, height, variants)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, m.URL, userID, m.Kind, m.ContentType, m.Size, m.Width, m.Height, variants)
+	return err
+}
+
+func (r *mediaRepo) GetByURL(url string) (*model.Media, error) {
+	var m model.Media
+	var width, height sql.NullInt64
+	var variants []byte
+	err := r.db.QueryRow(`
+		SELECT url, user_id, kind, content_type, size, width, height, variants
+		FROM media_uploads
+		WHERE url = $1
+	`, url).Scan(&m.URL, &m.UserID, &m.Kind, &m.ContentType, &m.Size, &width, &height, &variants)
+	if err != nil {
+		return nil, err
+	}
+	if width.Valid && height.Valid {
+		w, h := int(width.Int64), int(height.Int64)
+		m.Width, m.Height = &w, &h
+	}
+	if m.Variants, err = decodeVariants(variants); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *mediaRepo) Delete(url string) error {
+	_, err := r.db.Exec(`DELETE FROM media_uploads WHERE url = $1`, url)
+	return err
+}
+
+func decodeVariants(raw []byte) (map[string]string, error) {
+	variants := map[string]string{}
+	if raw == nil {
+		return variants, nil
+	}
+	if err := json.Unmarshal(raw, &variants); err != nil {
+		return nil, err
+	}
+	return variants, nil
+}
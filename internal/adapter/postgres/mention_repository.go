@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"WebMessanger/internal/model"
+	"database/sql"
+	"encoding/json"
+	"github.com/google/uuid"
+)
+
+// mentionsColumn builds a select expression returning the mentions of a source
+// as a JSON array, resolving user names at read time so renames are reflected.
+func mentionsColumn(sourceType, sourceIDExpr string) string {
+	return `COALESCE((
+		SELECT json_agg(json_build_object(
+			'user_id', m.user_id, 'user_name', mu.name, 'offset', m.start_offset, 'length', m.length
+		) ORDER BY m.start_offset)
+		FROM mentions m
+		JOIN users mu ON mu.id = m.user_id
+		WHERE m.source_type = '` + sourceType + `' AND m.source_id = ` + sourceIDExpr + `
+	), '[]')`
+}
+
+func decodeMentions(raw []byte) ([]*model.Mention, error) {
+	mentions := make([]*model.Mention, 0)
+	if err := json.Unmarshal(raw, &mentions); err != nil {
+		return nil, err
+	}
+	return mentions, nil
+}
+
+type mentionRepo struct {
+	db *sql.DB
+}
+
+func NewMentionRepo(db *sql.DB) *mentionRepo {
+	return &mentionRepo{db: db}
+}
+
+func (r *mentionRepo) ReplaceForSource(sourceType string, sourceID uuid.UUID, mentions []*model.Mention) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM mentions WHERE source_type = $1 AND source_id = $2`, sourceType, sourceID); err != nil {
+		return err
+	}
+	for _, m := range mentions {
+		_, err := tx.Exec(`
+			INSERT INTO mentions (id, source_type, source_id, user_id, start_offset, length)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, uuid.New(), sourceType, sourceID, m.UserID, m.Offset, m.Length)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *mentionRepo) GetMentionedUserIDs(sourceType string, sourceID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT user_id FROM mentions WHERE source_type = $1 AND source_id = $2`, sourceType, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,200 @@
+package postgres
+
+import (
+	"WebMessanger/internal/model"
+	"bytes"
+	"database/sql"
+	"github.com/google/uuid"
+	"strconv"
+)
+
+// conversationColumns is the select list of the conversation queries. It
+// expects conversations aliased as c, the viewer's membership as cm, the
+// other user as u, the latest message as lm, and the viewer bound as $1.
+const conversationColumns = `
+	c.id, c.created_at, u.id, u.name, u.avatar_url,
+	lm.id, lm.sender_id, lm.content, lm.created_at,
+	(
+		SELECT COUNT(*) FROM messages m
+		WHERE m.conversation_id = c.id AND m.sender_id <> $1 AND m.seq > cm.last_read_seq
+	),
+	EXISTS (
+		SELECT 1 FROM blocks b
+		WHERE (b.blocker_id = $1 AND b.blocked_id = u.id) OR (b.blocker_id = u.id AND b.blocked_id = $1)
+	)`
+
+// conversationFrom joins everything conversationColumns needs.
+const conversationFrom = `
+	FROM conversation_members cm
+	JOIN conversations c ON c.id = cm.conversation_id
+	JOIN users u ON u.id = CASE WHEN c.user_a = $1 THEN c.user_b ELSE c.user_a END
+	LEFT JOIN LATERAL (
+		SELECT m.id, m.sender_id, m.content, m.created_at
+		FROM messages m
+		WHERE m.conversation_id = c.id
+		ORDER BY m.seq DESC
+		LIMIT 1
+	) lm ON TRUE`
+
+type messageRepo struct {
+	db *sql.DB
+}
+
+func NewMessageRepo(db *sql.DB) *messageRepo {
+	return &messageRepo{db: db}
+}
+
+// GetOrCreateConversation returns the id of the conversation between the two
+// users, creating it when they have never talked before.
+func (r *messageRepo) GetOrCreateConversation(startedBy, otherID uuid.UUID) (uuid.UUID, error) {
+	userA, userB := startedBy, otherID
+	if bytes.Compare(userA[:], userB[:]) > 0 {
+		userA, userB = userB, userA
+	}
+	tx, err := r.db.Begin()
+	if err != nil {
+		return uuid.Nil, err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`
+		INSERT INTO conversations (id, user_a, user_b, started_by) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_a, user_b) DO NOTHING
+	`, uuid.New(), userA, userB, startedBy); err != nil {
+		return uuid.Nil, err
+	}
+	var id uuid.UUID
+	if err := tx.QueryRow(`SELECT id FROM conversations WHERE user_a = $1 AND user_b = $2`, userA, userB).Scan(&id); err != nil {
+		return uuid.Nil, err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO conversation_members (conversation_id, user_id) VALUES ($1, $2), ($1, $3)
+		ON CONFLICT DO NOTHING
+	`, id, userA, userB); err != nil {
+		return uuid.Nil, err
+	}
+	return id, tx.Commit()
+}
+
+// GetConversation returns the conversation if the user takes part in it.
+func (r *messageRepo) GetConversation(id, userID uuid.UUID) (*model.Conversation, error) {
+	row := r.db.QueryRow(`SELECT `+conversationColumns+conversationFrom+`
+		WHERE cm.user_id = $1 AND c.id = $2
+	`, userID, id)
+	return scanConversation(row)
+}
+
+// GetConversations lists the user's conversations, most recently active
+// first. Conversations someone else started show up once they have messages.
+func (r *messageRepo) GetConversations(userID uuid.UUID, limit, offset int) ([]*model.Conversation, error) {
+	rows, err := r.db.Query(`SELECT `+conversationColumns+conversationFrom+`
+		WHERE cm.user_id = $1 AND (c.last_message_at IS NOT NULL OR c.started_by = $1)
+		ORDER BY COALESCE(c.last_message_at, c.created_at) DESC, c.id DESC
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	conversations := make([]*model.Conversation, 0)
+	for rows.Next() {
+		c, err := scanConversation(rows)
+		if err != nil {
+			return nil, err
+		}
+		conversations = append(conversations, c)
+	}
+	return conversations, rows.Err()
+}
+
+func scanConversation(row rowScanner) (*model.Conversation, error) {
+	c := model.Conversation{With: &model.PublicUser{}}
+	var lastID, lastSender uuid.NullUUID
+	var lastContent sql.NullString
+	var lastAt sql.NullTime
+	if err := row.Scan(&c.ID, &c.CreatedAt, &c.With.ID, &c.With.Name, &c.With.AvatarURL,
+		&lastID, &lastSender, &lastContent, &lastAt, &c.UnreadCount, &c.Blocked); err != nil {
+		return nil, err
+	}
+	if lastID.Valid {
+		c.LastMessage = &model.Message{
+			ID:             lastID.UUID,
+			ConversationID: c.ID,
+			SenderID:       lastSender.UUID,
+			Content:        lastContent.String,
+			CreatedAt:      lastAt.Time,
+		}
+	}
+	return &c, nil
+}
+
+// AddMessage stores a message and marks the conversation read up to it for
+// the sender. The conversation row is locked before the message takes its
+// sequence number, so a conversation's messages commit in sequence order and
+// MarkRead never skips one that is still being written.
+func (r *messageRepo) AddMessage(msg *model.Message) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`UPDATE conversations SET last_message_at = $1 WHERE id = $2`, msg.CreatedAt, msg.ConversationID); err != nil {
+		return err
+	}
+	var seq int64
+	if err := tx.QueryRow(`
+		INSERT INTO messages (id, conversation_id, sender_id, content, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING seq
+	`, msg.ID, msg.ConversationID, msg.SenderID, msg.Content, msg.CreatedAt).Scan(&seq); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE conversation_members SET last_read_seq = $1
+		WHERE conversation_id = $2 AND user_id = $3
+	`, seq, msg.ConversationID, msg.SenderID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetMessages returns up to limit messages of a conversation, newest first,
+// starting after the given cursor.
+func (r *messageRepo) GetMessages(conversationID uuid.UUID, before *model.MessageCursor, limit int) ([]*model.Message, error) {
+	query := `
+		SELECT id, conversation_id, sender_id, content, created_at, seq
+		FROM messages
+		WHERE conversation_id = $1`
+	args := []interface{}{conversationID}
+	if before != nil {
+		args = append(args, before.Seq)
+		query += ` AND seq < $2`
+	}
+	args = append(args, limit)
+	query += `
+		ORDER BY seq DESC LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	messages := make([]*model.Message, 0)
+	for rows.Next() {
+		var m model.Message
+		if err := rows.Scan(&m.ID, &m.ConversationID, &m.SenderID, &m.Content, &m.CreatedAt, &m.Seq); err != nil {
+			return nil, err
+		}
+		messages = append(messages, &m)
+	}
+	return messages, rows.Err()
+}
+
+// MarkRead marks every message in the conversation as read by the user.
+func (r *messageRepo) MarkRead(conversationID, userID uuid.UUID) error {
+	_, err := r.db.Exec(`
+		UPDATE conversation_members
+		SET last_read_seq = GREATEST(last_read_seq, COALESCE((SELECT MAX(seq) FROM messages WHERE conversation_id = $1), 0))
+		WHERE conversation_id = $1 AND user_id = $2
+	`, conversationID, userID)
+	return err
+}
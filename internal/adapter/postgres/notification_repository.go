@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"WebMessanger/internal/model"
+	"database/sql"
+	"github.com/google/uuid"
+)
+
+type notificationRepo struct {
+	db *sql.DB
+}
+
+func NewNotificationRepo(db *sql.DB) *notificationRepo {
+	return &notificationRepo{db: db}
+}
+
+func (r *notificationRepo) Create(n *model.Notification) error {
+	_, err := r.db.Exec(`
+		INSERT INTO notifications (id, user_id, actor_id, type, thread_id, comment_id, is_read, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, n.ID, n.UserID, n.ActorID, n.Type, n.ThreadID, n.CommentID, n.IsRead, n.CreatedAt)
+	return err
+}
+
+func (r *notificationRepo) GetByUser(userID uuid.UUID, limit, offset int) ([]*model.Notification, error) {
+	rows, err := r.db.Query(`
+		SELECT n.id, n.user_id, n.actor_id, u.name, n.type, n.thread_id, n.comment_id, n.is_read, n.created_at
+		FROM notifications n
+		JOIN users u ON u.id = n.actor_id
+		WHERE n.user_id = $1
+		ORDER BY n.created_at DESC
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	notifications := make([]*model.Notification, 0)
+	for rows.Next() {
+		var n model.Notification
+		var threadID, commentID uuid.NullUUID
+		if err := rows.Scan(&n.ID, &n.UserID, &n.ActorID, &n.ActorName, &n.Type, &threadID, &commentID, &n.IsRead, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		if threadID.Valid {
+			n.ThreadID = &threadID.UUID
+		}
+		if commentID.Valid {
+			n.CommentID = &commentID.UUID
+		}
+		notifications = append(notifications, &n)
+	}
+	return notifications, nil
+}
+
+func (r *notificationRepo) CountUnread(userID uuid.UUID) (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND is_read = FALSE`, userID).Scan(&count)
+	return count, err
+}
+
+func (r *notificationRepo) MarkRead(id, userID uuid.UUID) error {
+	_, err := r.db.Exec(`UPDATE notifications SET is_read = TRUE WHERE id = $1 AND user_id = $2`, id, userID)
+	return err
+}
+
+func (r *notificationRepo) MarkAllRead(userID uuid.UUID) error {
+	_, err := r.db.Exec(`UPDATE notifications SET is_read = TRUE WHERE user_id = $1 AND is_read = FALSE`, userID)
+	return err
+}
@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+)
+
+type pinRepo struct {
+	db *sql.DB
+}
+
+func NewPinRepo(db *sql.DB) *pinRepo {
+	return &pinRepo{db: db}
+}
+
+func (r *pinRepo) GetPinnedIDs(userID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(`SELECT thread_id FROM pinned_threads WHERE user_id = $1 ORDER BY position ASC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Pin appends the thread to the user's pins unless they already have limit
+// pinned threads; it reports whether the thread was pinned.
+func (r *pinRepo) Pin(userID, threadID uuid.UUID, limit int) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+	// Serialize pin changes per user so the limit cannot be exceeded by racing requests.
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtext($1))`, "pins:"+userID.String()); err != nil {
+		return false, err
+	}
+	res, err := tx.Exec(`
+		INSERT INTO pinned_threads (user_id, thread_id, position)
+		SELECT $1, $2, COALESCE(MAX(position), -1) + 1
+		FROM pinned_threads
+		WHERE user_id = $1
+		HAVING COUNT(*) < $3
+	`, userID, threadID, limit)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, tx.Commit()
+}
+
+func (r *pinRepo) Unpin(userID, threadID uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM pinned_threads WHERE user_id = $1 AND thread_id = $2`, userID, threadID)
+	return err
+}
+
+func (r *pinRepo) Reorder(userID uuid.UUID, threadIDs []uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	for i, id := range threadIDs {
+		if _, err := tx.Exec(`UPDATE pinned_threads SET position = $1 WHERE user_id = $2 AND thread_id = $3`, i, userID, id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"database/sql"
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+	"os"
+	"testing"
+)
+
+// testDB connects to TEST_DATABASE_URL and creates the tables a test needs in
+// a schema of its own, dropped when the test ends. The test is skipped when
+// no database is configured.
+func testDB(t *testing.T, schema ...string) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// One connection keeps search_path in effect for every query.
+	db.SetMaxOpenConns(1)
+	name := "test_" + uuid.NewString()[:8]
+	t.Cleanup(func() {
+		db.Exec(`DROP SCHEMA ` + name + ` CASCADE`)
+		db.Close()
+	})
+	for _, stmt := range append([]string{`CREATE SCHEMA ` + name, `SET search_path TO ` + name}, schema...) {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+var pinSchema = []string{
+	`CREATE TABLE users (id UUID PRIMARY KEY)`,
+	`CREATE TABLE communities (id UUID PRIMARY KEY)`,
+	`CREATE TABLE threads (
+		id           UUID PRIMARY KEY,
+		user_id      UUID NOT NULL REFERENCES users (id),
+		community_id UUID REFERENCES communities (id) ON DELETE SET NULL,
+		deleted_at   TIMESTAMPTZ,
+		deleted_by   UUID
+	)`,
+	`CREATE TABLE pinned_threads (
+		user_id   UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+		thread_id UUID NOT NULL REFERENCES threads (id) ON DELETE CASCADE,
+		position  INT  NOT NULL,
+		PRIMARY KEY (user_id, thread_id)
+	)`,
+}
+
+func TestDeletingPinnedThreadFreesPinSlot(t *testing.T) {
+	tests := []struct {
+		name   string
+		delete func(db *sql.DB, thread, community, owner uuid.UUID) error
+	}{
+		{"thread deleted", func(db *sql.DB, thread, community, owner uuid.UUID) error {
+			return NewThreadRepo(db).Delete(thread, owner)
+		}},
+		{"community deleted", func(db *sql.DB, thread, community, owner uuid.UUID) error {
+			return NewCommunityRepo(db).Delete(community, owner)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t, pinSchema...)
+			owner, community := uuid.New(), uuid.New()
+			threads := []uuid.UUID{uuid.New(), uuid.New(), uuid.New(), uuid.New()}
+			mustExec(t, db, `INSERT INTO users (id) VALUES ($1)`, owner)
+			mustExec(t, db, `INSERT INTO communities (id) VALUES ($1)`, community)
+			for i, id := range threads {
+				var communityID *uuid.UUID
+				if i == 0 {
+					communityID = &community
+				}
+				mustExec(t, db, `INSERT INTO threads (id, user_id, community_id) VALUES ($1, $2, $3)`, id, owner, communityID)
+			}
+
+			pins := NewPinRepo(db)
+			for _, id := range threads[:3] {
+				if ok, err := pins.Pin(owner, id, 3); err != nil || !ok {
+					t.Fatalf("pin %v: %v, %v", id, ok, err)
+				}
+			}
+			if err := tt.delete(db, threads[0], community, owner); err != nil {
+				t.Fatal(err)
+			}
+
+			var deleted bool
+			if err := db.QueryRow(`SELECT deleted_at IS NOT NULL FROM threads WHERE id = $1`, threads[0]).Scan(&deleted); err != nil || !deleted {
+				t.Fatalf("thread soft-deleted: %v, %v", deleted, err)
+			}
+			ids, err := pins.GetPinnedIDs(owner)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(ids) != 2 || ids[0] != threads[1] || ids[1] != threads[2] {
+				t.Errorf("pinned %v, want %v", ids, threads[1:3])
+			}
+			if ok, err := pins.Pin(owner, threads[3], 3); err != nil || !ok {
+				t.Errorf("pinning into the freed slot: %v, %v", ok, err)
+			}
+		})
+	}
+}
+
+func mustExec(t *testing.T, db *sql.DB, query string, args ...interface{}) {
+	t.Helper()
+	if _, err := db.Exec(query, args...); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"WebMessanger/internal/model"
+	"database/sql"
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+// pollColumn selects the poll of thread t as JSON, including the votes cast
+// by the viewer bound as $1.
+const pollColumn = `(
+	SELECT json_build_object(
+		'id', p.id,
+		'multiple', p.multiple,
+		'closes_at', p.closes_at,
+		'options', (
+			SELECT json_agg(json_build_object(
+				'id', o.id, 'position', o.position, 'text', o.text,
+				'votes', (SELECT COUNT(*) FROM poll_votes v WHERE v.option_id = o.id)
+			) ORDER BY o.position)
+			FROM poll_options o
+			WHERE o.poll_id = p.id
+		),
+		'total_voters', (SELECT COUNT(DISTINCT v.user_id) FROM poll_votes v WHERE v.poll_id = p.id),
+		'my_votes', COALESCE((SELECT json_agg(v.option_id) FROM poll_votes v WHERE v.poll_id = p.id AND v.user_id = $1), '[]')
+	)
+	FROM polls p
+	WHERE p.thread_id = t.id
+)`
+
+func decodePoll(raw []byte) (*model.Poll, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	var p model.Poll
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, err
+	}
+	p.ApplyResultVisibility(time.Now())
+	return &p, nil
+}
+
+type pollRepo struct {
+	db *sql.DB
+}
+
+func NewPollRepo(db *sql.DB) *pollRepo {
+	return &pollRepo{db: db}
+}
+
+func (r *pollRepo) Create(threadID uuid.UUID, poll *model.Poll) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec(`INSERT INTO polls (id, thread_id, multiple, closes_at) VALUES ($1, $2, $3, $4)`,
+		poll.ID, threadID, poll.Multiple, poll.ClosesAt)
+	if err != nil {
+		return err
+	}
+	for _, o := range poll.Options {
+		_, err := tx.Exec(`INSERT INTO poll_options (id, poll_id, position, text) VALUES ($1, $2, $3, $4)`,
+			o.ID, poll.ID, o.Position, o.Text)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *pollRepo) GetByThread(threadID, viewerID uuid.UUID) (*model.Poll, error) {
+	var raw []byte
+	err := r.db.QueryRow(`SELECT `+pollColumn+` FROM threads t WHERE t.id = $2 AND `+visibleToViewer, viewerID, threadID).Scan(&raw)
+	if err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, sql.ErrNoRows
+	}
+	return decodePoll(raw)
+}
+
+func (r *pollRepo) ReplaceVotes(pollID, userID uuid.UUID, optionIDs []uuid.UUID) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	// Lock the poll so concurrent ballots from the same user cannot interleave.
+	if _, err := tx.Exec(`SELECT id FROM polls WHERE id = $1 FOR UPDATE`, pollID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM poll_votes WHERE poll_id = $1 AND user_id = $2`, pollID, userID); err != nil {
+		return err
+	}
+	for _, optionID := range optionIDs {
+		_, err := tx.Exec(`INSERT INTO poll_votes (poll_id, option_id, user_id) VALUES ($1, $2, $3)`, pollID, optionID, userID)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"WebMessanger/internal/model"
+	"database/sql"
+	"github.com/google/uuid"
+)
+
+type revisionRepo struct {
+	db *sql.DB
+}
+
+func NewRevisionRepo(db *sql.DB) *revisionRepo {
+	return &revisionRepo{db: db}
+}
+
+// GetByTarget returns the earlier versions of a thread or comment, oldest
+// first.
+func (r *revisionRepo) GetByTarget(targetType string, targetID uuid.UUID) ([]*model.Revision, error) {
+	rows, err := r.db.Query(`
+		SELECT id, target_type, target_id, title, content, created_at
+		FROM revisions
+		WHERE target_type = $1 AND target_id = $2
+		ORDER BY created_at ASC
+	`, targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	revisions := make([]*model.Revision, 0)
+	for rows.Next() {
+		var rev model.Revision
+		if err := rows.Scan(&rev.ID, &rev.TargetType, &rev.TargetID, &rev.Title, &rev.Content, &rev.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, &rev)
+	}
+	return revisions, rows.Err()
+}
@@ -0,0 +1,73 @@
+package postgres
+
+import (
+	"WebMessanger/internal/model"
+	"database/sql"
+	"github.com/google/uuid"
+	"strings"
+)
+
+type tagRepo struct {
+	db *sql.DB
+}
+
+func NewTagRepo(db *sql.DB) *tagRepo {
+	return &tagRepo{db: db}
+}
+
+// SetThreadTags replaces the tag set of a thread.
+func (r *tagRepo) SetThreadTags(threadID uuid.UUID, tags []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`DELETE FROM thread_tags WHERE thread_id = $1`, threadID); err != nil {
+		return err
+	}
+	for _, name := range tags {
+		var tagID uuid.UUID
+		err := tx.QueryRow(`
+			INSERT INTO tags (id, name) VALUES ($1, $2)
+			ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+			RETURNING id
+		`, uuid.New(), name).Scan(&tagID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO thread_tags (thread_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`, threadID, tagID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Autocomplete counts only threads anyone can see, so a tag's suggestion
+// does not reveal private, unpublished or expired threads.
+func (r *tagRepo) Autocomplete(prefix string, limit int) ([]*model.Tag, error) {
+	pattern := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix) + "%"
+	rows, err := r.db.Query(`
+		SELECT tg.name, COUNT(tt.thread_id) AS thread_count
+		FROM tags tg
+		JOIN thread_tags tt ON tt.tag_id = tg.id
+		JOIN threads t ON t.id = tt.thread_id
+		WHERE tg.name LIKE $1 AND t.status = 'published' AND t.visibility = 'public'
+			AND `+notExpired+` AND `+notDeleted+`
+		GROUP BY tg.name
+		ORDER BY thread_count DESC, tg.name ASC
+		LIMIT $2
+	`, pattern, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	tags := make([]*model.Tag, 0)
+	for rows.Next() {
+		var t model.Tag
+		if err := rows.Scan(&t.Name, &t.ThreadCount); err != nil {
+			return nil, err
+		}
+		tags = append(tags, &t)
+	}
+	return tags, nil
+}
@@ -4,9 +4,105 @@ import (
 	"WebMessanger/internal/model"
 	"WebMessanger/internal/usecase"
 	"database/sql"
+	"encoding/json"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"time"
 )
 
+// threadColumns is the select list shared by every thread read query; it
+// expects threads aliased as t, users as u, and the viewer bound as $1.
+var threadColumns = `
+	t.id, t.user_id, u.name, u.avatar_url, t.title, t.content, COALESCE(t.content_html, ''), t.created_at,
+	COALESCE((
+		SELECT array_agg(tg.name ORDER BY tg.name)
+		FROM thread_tags tt
+		JOIN tags tg ON tg.id = tt.tag_id
+		WHERE tt.thread_id = t.id
+	), '{}'),
+	` + mentionsColumn(model.MentionSourceThread, "t.id") + `,
+	t.quote_of_id,
+	(
+		SELECT json_build_object(
+			'id', q.id, 'user_id', q.user_id, 'user_name', qu.name, 'avatar_url', qu.avatar_url,
+			'content', q.content, 'content_html', COALESCE(q.content_html, ''), 'media_url', (
+				SELECT qa.url FROM thread_attachments qa WHERE qa.thread_id = q.id ORDER BY qa.position LIMIT 1
+			)
+		)
+		FROM threads q
+		JOIN users qu ON qu.id = q.user_id
+		WHERE q.id = t.quote_of_id AND q.status = 'published' AND q.visibility = 'public'
+		  AND (q.expires_at IS NULL OR q.expires_at > NOW()) AND q.deleted_at IS NULL
+	),
+	(SELECT COUNT(*) FROM reposts rp WHERE rp.thread_id = t.id),
+	(SELECT COUNT(*) FROM threads qt WHERE qt.quote_of_id = t.id AND qt.status = 'published'
+		AND (qt.expires_at IS NULL OR qt.expires_at > NOW()) AND qt.deleted_at IS NULL),
+	EXISTS (SELECT 1 FROM bookmarks b WHERE b.thread_id = t.id AND b.user_id = $1),
+	` + pollColumn + `,
+	t.status, t.publish_at, t.visibility, t.reply_policy, COALESCE(t.preview_url, ''),
+	` + previewColumn + `,
+	` + variantsColumn("u.avatar_url") + `,
+	` + attachmentsColumn + `,
+	t.sensitive, t.content_warning, t.flagged_by_moderator,
+	t.sensitive AND t.user_id <> $1 AND ` + viewerSensitiveMedia + ` <> 'show',
+	t.ttl_seconds, t.expires_at,
+	t.community_id, (SELECT cm.name FROM communities cm WHERE cm.id = t.community_id),
+	t.category_id, (SELECT cg.name FROM categories cg WHERE cg.id = t.category_id),
+	t.locked, t.locked_by, t.locked_at, t.edited_at, t.deleted_at`
+
+// attachmentsColumn selects the attachments of thread t, in order, as a JSON
+// array.
+var attachmentsColumn = `COALESCE((
+	SELECT json_agg(json_build_object(
+		'id', a.id, 'position', a.position, 'type', a.type, 'url', a.url,
+		'width', a.width, 'height', a.height, 'alt_text', a.alt_text,
+		'variants', ` + variantsColumn("a.url") + `
+	) ORDER BY a.position)
+	FROM thread_attachments a
+	WHERE a.thread_id = t.id
+), '[]')`
+
+// viewerSensitiveMedia is the sensitive-media preference of the viewer bound
+// as $1.
+const viewerSensitiveMedia = `COALESCE((SELECT vu.sensitive_media FROM users vu WHERE vu.id = $1), 'blur')`
+
+// hideSensitive drops other people's sensitive threads from feed-style
+// listings when the viewer chose to hide them.
+const hideSensitive = `(NOT t.sensitive OR t.user_id = $1 OR ` + viewerSensitiveMedia + ` <> 'hide')`
+
+// notExpired hides threads past their TTL even before the reaper has deleted
+// them.
+const notExpired = `(t.expires_at IS NULL OR t.expires_at > NOW())`
+
+// notDeleted hides soft-deleted threads, which stay in the table until the
+// purge job removes them.
+const notDeleted = `t.deleted_at IS NULL`
+
+// visibleToViewer limits thread reads to what the viewer may see: their own
+// threads, and published threads whose visibility setting includes them.
+// Expired and deleted threads are hidden from everyone, their author included.
+const visibleToViewer = `(` + notDeleted + ` AND ` + notExpired + ` AND (t.user_id = $1 OR (t.status = 'published' AND (
+	t.visibility = 'public'
+	OR (t.visibility = 'followers' AND EXISTS (
+		SELECT 1 FROM follows f WHERE f.follower_id = $1 AND f.followee_id = t.user_id
+	))
+	OR (t.visibility = 'mentioned' AND EXISTS (
+		SELECT 1 FROM mentions m WHERE m.source_type = 'thread' AND m.source_id = t.id AND m.user_id = $1
+	))
+))))`
+
+// The feed column sets extend threadColumns with repost attribution for
+// queries that mix original threads with reposts; originals select NULLs.
+const (
+	feedOriginalColumns    = `NULL::uuid, NULL::text, NULL::timestamptz, t.created_at AS activity_at, NULL::int AS pin_position`
+	profileOriginalColumns = `NULL::uuid, NULL::text, NULL::timestamptz, t.created_at AS activity_at, p.position AS pin_position`
+	feedRepostColumns      = `ru.id, ru.name, r.created_at, r.created_at AS activity_at, NULL::int AS pin_position`
+)
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
 type threadRepo struct {
 	db *sql.DB
 }
@@ -16,88 +112,594 @@ func NewThreadRepo(db *sql.DB) usecase.ThreadRepository {
 }
 
 func (r *threadRepo) Create(thr *model.Thread) (*model.Thread, error) {
-	_, err := r.db.Exec(`INSERT INTO threads (id, user_id, content, media_url, created_at) VALUES ($1, $2, $3, $4, $5)`,
-		thr.ID, thr.UserID, thr.Content, thr.MediaURL, thr.CreatedAt)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	_, err = tx.Exec(`
+		INSERT INTO threads (id, user_id, content, content_html, quote_of_id, status, publish_at, visibility, reply_policy, preview_url,
+			sensitive, content_warning, ttl_seconds, expires_at, community_id, title, category_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), $11, $12, $13, $14, $15, $16, $17, $18)
+	`, thr.ID, thr.UserID, thr.Content, thr.ContentHTML, thr.QuoteOfID, thr.Status, thr.PublishAt, thr.Visibility, thr.ReplyPolicy, thr.PreviewURL,
+		thr.Sensitive, thr.ContentWarning, ttlSeconds(thr.TTL), thr.ExpiresAt, thr.CommunityID, thr.Title, thr.CategoryID, thr.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
+	if err := insertAttachments(tx, thr.ID, thr.Attachments); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 	return thr, nil
 }
 
 func (r *threadRepo) Update(thr *model.Thread) (*model.Thread, error) {
-	_, err := r.db.Exec(`UPDATE threads SET content = $1, media_url = $2 WHERE id = $3`, thr.Content, thr.MediaURL, thr.ID)
+	tx, err := r.db.Begin()
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`
+		INSERT INTO revisions (id, target_type, target_id, title, content, created_at)
+		SELECT $1, 'thread', id, title, content, COALESCE(edited_at, created_at)
+		FROM threads
+		WHERE id = $2 AND (title <> $3 OR content <> $4)
+	`, uuid.New(), thr.ID, thr.Title, thr.Content); err != nil {
+		return nil, err
+	}
+	err = tx.QueryRow(`
+		UPDATE threads
+		SET content = $1, content_html = $2, visibility = $3, reply_policy = $4, preview_url = NULLIF($5, ''),
+		    sensitive = $6, content_warning = $7, title = $8, category_id = $9,
+		    edited_at = CASE WHEN title <> $8 OR content <> $1 THEN NOW() ELSE edited_at END
+		WHERE id = $10
+		RETURNING edited_at
+	`, thr.Content, thr.ContentHTML, thr.Visibility, thr.ReplyPolicy, thr.PreviewURL, thr.Sensitive, thr.ContentWarning,
+		thr.Title, thr.CategoryID, thr.ID).Scan(&thr.EditedAt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`DELETE FROM thread_attachments WHERE thread_id = $1`, thr.ID); err != nil {
+		return nil, err
+	}
+	if err := insertAttachments(tx, thr.ID, thr.Attachments); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
 	return thr, nil
 }
 
-func (r *threadRepo) Delete(id uuid.UUID) error {
-	_, err := r.db.Exec(`DELETE FROM threads WHERE id = $1`, id)
+func insertAttachments(tx *sql.Tx, threadID uuid.UUID, attachments []*model.Attachment) error {
+	for _, a := range attachments {
+		if _, err := tx.Exec(`
+			INSERT INTO thread_attachments (id, thread_id, position, type, url, width, height, alt_text)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, a.ID, threadID, a.Position, a.Type, a.URL, a.Width, a.Height, a.AltText); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Delete soft-deletes a thread, recording who deleted it. The row is kept
+// until PurgeDeleted removes it, but its pin is dropped right away so it no
+// longer takes one of the owner's pin slots; restoring does not re-pin it.
+func (r *threadRepo) Delete(id, deletedBy uuid.UUID) error {
+	_, err := r.db.Exec(`
+		WITH deleted AS (
+			UPDATE threads SET deleted_at = NOW(), deleted_by = $2
+			WHERE id = $1 AND deleted_at IS NULL
+			RETURNING id
+		)
+		DELETE FROM pinned_threads WHERE thread_id IN (SELECT id FROM deleted)
+	`, id, deletedBy)
 	return err
 }
 
-func (r *threadRepo) GetAllThreads() ([]*model.Thread, error) {
+// GetDeletedByUser returns the threads the user deleted themselves since the
+// given time, most recently deleted first.
+func (r *threadRepo) GetDeletedByUser(userID uuid.UUID, since time.Time) ([]*model.Thread, error) {
 	rows, err := r.db.Query(`
-		SELECT t.id, t.user_id, u.name,u.avatar_url, t.content, t.media_url, t.created_at
+		SELECT `+threadColumns+`
 		FROM threads t
 		JOIN users u ON t.user_id = u.id
-		ORDER BY t.created_at DESC
-	`)
+		WHERE t.user_id = $1 AND t.deleted_by = $1 AND t.deleted_at > $2 AND `+notExpired+`
+		ORDER BY t.deleted_at DESC
+	`, userID, since)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
+	return scanThreads(rows)
+}
 
-	var threads []*model.Thread
+// Restore undeletes a thread the user deleted themselves since the given
+// time, and reports whether there was such a thread.
+func (r *threadRepo) Restore(id, userID uuid.UUID, since time.Time) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE threads t
+		SET deleted_at = NULL, deleted_by = NULL
+		WHERE t.id = $1 AND t.user_id = $2 AND t.deleted_by = $2 AND t.deleted_at > $3 AND `+notExpired+`
+	`, id, userID, since)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// DeleteExpired deletes up to limit threads whose TTL ran out by now and
+// returns how many it removed.
+func (r *threadRepo) DeleteExpired(now time.Time, limit int) (int, error) {
+	return r.deleteBatch(`
+		SELECT id
+		FROM threads
+		WHERE expires_at <= $1
+		ORDER BY expires_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, now, limit)
+}
+
+// PurgeDeleted permanently deletes up to limit threads that were soft-deleted
+// before the given time and returns how many it removed.
+func (r *threadRepo) PurgeDeleted(before time.Time, limit int) (int, error) {
+	return r.deleteBatch(`
+		SELECT id
+		FROM threads
+		WHERE deleted_at < $1
+		ORDER BY deleted_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`, before, limit)
+}
+
+// deleteBatch hard-deletes the threads whose ids the query selects. The query
+// should claim its rows with SKIP LOCKED so several replicas can run the
+// background jobs at once.
+func (r *threadRepo) deleteBatch(query string, args ...interface{}) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	ids, err := selectIDs(tx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	if err := deleteThreads(tx, ids); err != nil {
+		return 0, err
+	}
+	return len(ids), tx.Commit()
+}
+
+// selectIDs runs a query that selects a single id column and returns the ids
+// as strings, ready for pq.Array.
+func selectIDs(tx *sql.Tx, query string, args ...interface{}) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var ids []string
 	for rows.Next() {
-		var t model.Thread
-		err := rows.Scan(&t.ID, &t.UserID, &t.UserName, &t.AvatarURL, &t.Content, &t.MediaURL, &t.CreatedAt)
-		if err != nil {
+		var id string
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		threads = append(threads, &t)
+		ids = append(ids, id)
 	}
-	return threads, nil
+	return ids, rows.Err()
 }
 
-func (r *threadRepo) GetThreadById(id uuid.UUID) (*model.Thread, error) {
-	var t model.Thread
-	err := r.db.QueryRow(`
-		SELECT t.id, t.user_id, u.name, u.avatar_url, t.content, t.media_url, t.created_at
+// deleteThreads removes the given threads along with their comments and the
+// likes, mentions, revisions and bookmarks that point at them. Tags,
+// attachments, polls, reposts and notifications go with the thread through
+// ON DELETE CASCADE.
+func deleteThreads(tx *sql.Tx, ids []string) error {
+	if _, err := tx.Exec(`
+		DELETE FROM likes
+		WHERE (target_type = 'thread' AND target_id = ANY($1::uuid[]))
+		   OR (target_type = 'comment' AND target_id IN (SELECT id FROM comments WHERE thread_id = ANY($1::uuid[])))
+	`, pq.Array(ids)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM mentions
+		WHERE (source_type = 'thread' AND source_id = ANY($1::uuid[]))
+		   OR (source_type = 'comment' AND source_id IN (SELECT id FROM comments WHERE thread_id = ANY($1::uuid[])))
+	`, pq.Array(ids)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM revisions
+		WHERE (target_type = 'thread' AND target_id = ANY($1::uuid[]))
+		   OR (target_type = 'comment' AND target_id IN (SELECT id FROM comments WHERE thread_id = ANY($1::uuid[])))
+	`, pq.Array(ids)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM bookmarks WHERE thread_id = ANY($1::uuid[])`, pq.Array(ids)); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM comments WHERE thread_id = ANY($1::uuid[])`, pq.Array(ids)); err != nil {
+		return err
+	}
+	_, err := tx.Exec(`DELETE FROM threads WHERE id = ANY($1::uuid[])`, pq.Array(ids))
+	return err
+}
+
+func (r *threadRepo) GetAllThreads(viewerID uuid.UUID) ([]*model.Thread, error) {
+	rows, err := r.db.Query(`
+		SELECT `+threadColumns+`, `+feedOriginalColumns+`
 		FROM threads t
 		JOIN users u ON t.user_id = u.id
-		WHERE t.id = $1
-	`, id).
-		Scan(&t.ID, &t.UserID, &t.UserName, &t.AvatarURL, &t.Content, &t.MediaURL, &t.CreatedAt)
+		WHERE `+visibleToViewer+` AND `+hideSensitive+`
+		UNION ALL
+		SELECT `+threadColumns+`, `+feedRepostColumns+`
+		FROM reposts r
+		JOIN threads t ON t.id = r.thread_id
+		JOIN users u ON t.user_id = u.id
+		JOIN users ru ON ru.id = r.user_id
+		WHERE `+visibleToViewer+` AND `+hideSensitive+`
+		ORDER BY activity_at DESC
+	`, viewerID)
 	if err != nil {
 		return nil, err
 	}
-	return &t, nil
+	defer rows.Close()
+	return scanFeed(rows)
+}
+
+func (r *threadRepo) GetThreadById(id, viewerID uuid.UUID) (*model.Thread, error) {
+	row := r.db.QueryRow(`
+		SELECT `+threadColumns+`
+		FROM threads t
+		JOIN users u ON t.user_id = u.id
+		WHERE t.id = $2 AND `+visibleToViewer+`
+	`, viewerID, id)
+	return scanThread(row)
+}
+
+// GetForModeration loads a thread whatever its visibility setting, so
+// moderators can act on threads they are not in the audience of. Deleted and
+// expired threads are still not found. The moderator is bound as the viewer.
+func (r *threadRepo) GetForModeration(id, moderatorID uuid.UUID) (*model.Thread, error) {
+	row := r.db.QueryRow(`
+		SELECT `+threadColumns+`
+		FROM threads t
+		JOIN users u ON t.user_id = u.id
+		WHERE t.id = $2 AND `+notDeleted+` AND `+notExpired+`
+	`, moderatorID, id)
+	return scanThread(row)
+}
+
+// GetByUser returns the user's pinned threads first, followed by their threads
+// and reposts in chronological order.
+func (r *threadRepo) GetByUser(userID, viewerID uuid.UUID) ([]*model.Thread, error) {
+	rows, err := r.db.Query(`
+		SELECT `+threadColumns+`, `+profileOriginalColumns+`
+		FROM threads t
+		JOIN users u ON t.user_id = u.id
+		LEFT JOIN pinned_threads p ON p.thread_id = t.id
+		WHERE t.user_id = $2 AND `+visibleToViewer+` AND `+hideSensitive+`
+		UNION ALL
+		SELECT `+threadColumns+`, `+feedRepostColumns+`
+		FROM reposts r
+		JOIN threads t ON t.id = r.thread_id
+		JOIN users u ON t.user_id = u.id
+		JOIN users ru ON ru.id = r.user_id
+		WHERE r.user_id = $2 AND `+visibleToViewer+` AND `+hideSensitive+`
+		ORDER BY pin_position ASC NULLS LAST, activity_at DESC
+	`, viewerID, userID)
+
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanFeed(rows)
 }
 
-func (r *threadRepo) GetByUser(userID uuid.UUID) ([]*model.Thread, error) {
+func (r *threadRepo) GetByTag(tag string, viewerID uuid.UUID, limit, offset int) ([]*model.Thread, error) {
 	rows, err := r.db.Query(`
-		SELECT t.id, t.user_id, u.name, u.avatar_url, t.content, t.media_url, t.created_at
+		SELECT `+threadColumns+`
 		FROM threads t
 		JOIN users u ON t.user_id = u.id
-		WHERE t.user_id = $1
+		JOIN thread_tags tt ON tt.thread_id = t.id
+		JOIN tags tg ON tg.id = tt.tag_id
+		WHERE tg.name = $2 AND `+visibleToViewer+` AND `+hideSensitive+`
 		ORDER BY t.created_at DESC
+		LIMIT $3 OFFSET $4
+	`, viewerID, tag, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanThreads(rows)
+}
+
+// GetByCommunity returns the threads posted into a community, newest first.
+func (r *threadRepo) GetByCommunity(communityID, viewerID uuid.UUID, limit, offset int) ([]*model.Thread, error) {
+	rows, err := r.db.Query(`
+		SELECT `+threadColumns+`
+		FROM threads t
+		JOIN users u ON t.user_id = u.id
+		WHERE t.community_id = $2 AND `+visibleToViewer+` AND `+hideSensitive+`
+		ORDER BY t.created_at DESC
+		LIMIT $3 OFFSET $4
+	`, viewerID, communityID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanThreads(rows)
+}
+
+// GetByCategory returns the threads filed under a category, newest first.
+func (r *threadRepo) GetByCategory(categoryID, viewerID uuid.UUID, limit, offset int) ([]*model.Thread, error) {
+	rows, err := r.db.Query(`
+		SELECT `+threadColumns+`
+		FROM threads t
+		JOIN users u ON t.user_id = u.id
+		WHERE t.category_id = $2 AND `+visibleToViewer+` AND `+hideSensitive+`
+		ORDER BY t.created_at DESC
+		LIMIT $3 OFFSET $4
+	`, viewerID, categoryID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanThreads(rows)
+}
+
+func (r *threadRepo) AddRepost(id, threadID, userID uuid.UUID, createdAt time.Time) error {
+	_, err := r.db.Exec(`
+		INSERT INTO reposts (id, user_id, thread_id, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, thread_id) DO NOTHING
+	`, id, userID, threadID, createdAt)
+	return err
+}
+
+func (r *threadRepo) RemoveRepost(threadID, userID uuid.UUID) error {
+	_, err := r.db.Exec(`DELETE FROM reposts WHERE thread_id = $1 AND user_id = $2`, threadID, userID)
+	return err
+}
+
+func (r *threadRepo) GetDrafts(userID uuid.UUID) ([]*model.Thread, error) {
+	rows, err := r.db.Query(`
+		SELECT `+threadColumns+`
+		FROM threads t
+		JOIN users u ON t.user_id = u.id
+		WHERE t.user_id = $1 AND t.status <> 'published' AND `+notDeleted+`
+		ORDER BY t.publish_at ASC NULLS LAST, t.created_at DESC
 	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanThreads(rows)
+}
+
+// SetStatus also starts the TTL clock of a thread that becomes published.
+func (r *threadRepo) SetStatus(id uuid.UUID, status string, publishAt *time.Time, createdAt time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE threads
+		SET status = $1, publish_at = $2, created_at = $3,
+		    expires_at = CASE WHEN $1 = 'published' THEN $3 + ttl_seconds * INTERVAL '1 second' END
+		WHERE id = $4
+	`, status, publishAt, createdAt, id)
+	return err
+}
 
+// PublishDue publishes scheduled threads whose time has come. Rows are claimed
+// with SKIP LOCKED so several replicas can run the scheduler concurrently
+// without publishing the same thread twice.
+func (r *threadRepo) PublishDue(now time.Time, limit int) ([]*model.Thread, error) {
+	rows, err := r.db.Query(`
+		WITH due AS (
+			SELECT id
+			FROM threads
+			WHERE status = 'scheduled' AND publish_at <= $1 AND deleted_at IS NULL
+			ORDER BY publish_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE threads t
+		SET status = 'published', created_at = t.publish_at, publish_at = NULL,
+		    expires_at = t.publish_at + t.ttl_seconds * INTERVAL '1 second'
+		FROM due
+		WHERE t.id = due.id
+		RETURNING t.id, t.user_id, t.content, t.created_at
+	`, now, limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
+	var threads []*model.Thread
+	for rows.Next() {
+		t := model.Thread{Status: model.ThreadStatusPublished}
+		if err := rows.Scan(&t.ID, &t.UserID, &t.Content, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		threads = append(threads, &t)
+	}
+	return threads, rows.Err()
+}
+
+// SetSensitive updates the sensitive flag and content warning. byModerator
+// records whether a moderator, rather than the author, set the flag.
+func (r *threadRepo) SetSensitive(id uuid.UUID, sensitive bool, contentWarning string, byModerator bool) error {
+	_, err := r.db.Exec(`
+		UPDATE threads SET sensitive = $1, content_warning = $2, flagged_by_moderator = $3 WHERE id = $4
+	`, sensitive, contentWarning, byModerator, id)
+	return err
+}
 
+// SetLocked locks the thread on behalf of lockedBy, or unlocks it when
+// locked is false, and writes entry to the audit log in the same
+// transaction.
+func (r *threadRepo) SetLocked(id uuid.UUID, locked bool, lockedBy *uuid.UUID, lockedAt *time.Time, entry *model.AuditEntry) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(`UPDATE threads SET locked = $1, locked_by = $2, locked_at = $3 WHERE id = $4`,
+		locked, lockedBy, lockedAt, id); err != nil {
+		return err
+	}
+	if err := recordAudit(tx, entry); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetUnrendered returns up to limit threads whose content_html has not been
+// filled in yet. Only ID and Content are set.
+func (r *threadRepo) GetUnrendered(limit int) ([]*model.Thread, error) {
+	rows, err := r.db.Query(`SELECT id, content FROM threads WHERE content_html IS NULL LIMIT $1`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 	var threads []*model.Thread
 	for rows.Next() {
 		var t model.Thread
-		err := rows.Scan(&t.ID, &t.UserID, &t.UserName, &t.AvatarURL, &t.Content, &t.MediaURL, &t.CreatedAt)
-		if err != nil {
+		if err := rows.Scan(&t.ID, &t.Content); err != nil {
 			return nil, err
 		}
 		threads = append(threads, &t)
 	}
+	return threads, rows.Err()
+}
+
+func (r *threadRepo) SetContentHTML(id uuid.UUID, contentHTML string) error {
+	_, err := r.db.Exec(`UPDATE threads SET content_html = $1 WHERE id = $2`, contentHTML, id)
+	return err
+}
+
+func scanThread(row rowScanner, extra ...interface{}) (*model.Thread, error) {
+	var t model.Thread
+	var tags pq.StringArray
+	var mentions, quoted, poll, preview, avatarVariants, attachments []byte
+	var quoteOfID, communityID, categoryID, lockedBy uuid.NullUUID
+	var communityName, categoryName sql.NullString
+	var ttl sql.NullInt64
+	dest := []interface{}{
+		&t.ID, &t.UserID, &t.UserName, &t.AvatarURL, &t.Title, &t.Content, &t.ContentHTML, &t.CreatedAt,
+		&tags, &mentions, &quoteOfID, &quoted, &t.RepostCount, &t.QuoteCount, &t.BookmarkedByMe, &poll,
+		&t.Status, &t.PublishAt, &t.Visibility, &t.ReplyPolicy, &t.PreviewURL, &preview, &avatarVariants, &attachments,
+		&t.Sensitive, &t.ContentWarning, &t.FlaggedByModerator, &t.Blurred, &ttl, &t.ExpiresAt,
+		&communityID, &communityName, &categoryID, &categoryName,
+		&t.Locked, &lockedBy, &t.LockedAt, &t.EditedAt, &t.DeletedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	t.Tags = tags
+	t.TTL = ttlName(ttl)
+	if communityID.Valid {
+		t.CommunityID = &communityID.UUID
+		t.CommunityName = communityName.String
+	}
+	if lockedBy.Valid {
+		t.LockedBy = &lockedBy.UUID
+	}
+	if categoryID.Valid {
+		t.CategoryID = &categoryID.UUID
+		t.CategoryName = categoryName.String
+	}
+	var err error
+	if t.Mentions, err = decodeMentions(mentions); err != nil {
+		return nil, err
+	}
+	if t.Poll, err = decodePoll(poll); err != nil {
+		return nil, err
+	}
+	if t.Preview, err = decodePreview(preview); err != nil {
+		return nil, err
+	}
+	if t.AvatarVariants, err = decodeVariants(avatarVariants); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(attachments, &t.Attachments); err != nil {
+		return nil, err
+	}
+	if len(t.Attachments) > 0 {
+		t.MediaURL = t.Attachments[0].URL
+		t.MediaVariants = t.Attachments[0].Variants
+	}
+	if quoteOfID.Valid {
+		t.QuoteOfID = &quoteOfID.UUID
+		t.Quoted = &model.QuotedThread{ID: quoteOfID.UUID, Unavailable: true, Content: model.UnavailableThreadContent}
+		if quoted != nil {
+			if err := json.Unmarshal(quoted, t.Quoted); err != nil {
+				return nil, err
+			}
+			t.Quoted.Unavailable = false
+		}
+	}
+	return &t, nil
+}
+
+// ttlSeconds converts a TTL name from model.ThreadTTLs to the stored number
+// of seconds; an empty name is stored as NULL.
+func ttlSeconds(ttl string) sql.NullInt64 {
+	d, ok := model.ThreadTTLs[ttl]
+	if !ok {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(d / time.Second), Valid: true}
+}
+
+func ttlName(seconds sql.NullInt64) string {
+	if !seconds.Valid {
+		return ""
+	}
+	for name, d := range model.ThreadTTLs {
+		if int64(d/time.Second) == seconds.Int64 {
+			return name
+		}
+	}
+	return ""
+}
+
+func scanThreads(rows *sql.Rows) ([]*model.Thread, error) {
+	var threads []*model.Thread
+	for rows.Next() {
+		t, err := scanThread(rows)
+		if err != nil {
+			return nil, err
+		}
+		threads = append(threads, t)
+	}
+	return threads, nil
+}
+
+func scanFeed(rows *sql.Rows) ([]*model.Thread, error) {
+	var threads []*model.Thread
+	for rows.Next() {
+		var reposterID uuid.NullUUID
+		var reposterName sql.NullString
+		var repostedAt sql.NullTime
+		var activityAt time.Time
+		var pinPosition sql.NullInt64
+		t, err := scanThread(rows, &reposterID, &reposterName, &repostedAt, &activityAt, &pinPosition)
+		if err != nil {
+			return nil, err
+		}
+		t.Pinned = pinPosition.Valid
+		if reposterID.Valid {
+			t.RepostedBy = &model.Repost{UserID: reposterID.UUID, UserName: reposterName.String, RepostedAt: repostedAt.Time}
+		}
+		threads = append(threads, t)
+	}
 	return threads, nil
 }
@@ -51,6 +51,7 @@ func (r *userRepo) GetByName(name string) (*model.User, error) {
 
 func (r *userRepo) GetByID(id uuid.UUID) (*model.User, error) {
 	var user model.User
+	var avatarVariants []byte
 	err := r.db.QueryRow(`
         SELECT
             id,
@@ -60,7 +61,8 @@ func (r *userRepo) GetByID(id uuid.UUID) (*model.User, error) {
             location,
             social_links,
             avatar_url,
-            created_at, is_verified
+            created_at, is_verified, sensitive_media, role,
+            `+variantsColumn("users.avatar_url")+`
         FROM users
         WHERE id = $1
     `, id).Scan(
@@ -73,10 +75,16 @@ func (r *userRepo) GetByID(id uuid.UUID) (*model.User, error) {
 		&user.AvatarURL,
 		&user.CreatedAt,
 		&user.IsVerified,
+		&user.SensitiveMedia,
+		&user.Role,
+		&avatarVariants,
 	)
 	if err != nil {
 		return nil, err
 	}
+	if user.AvatarVariants, err = decodeVariants(avatarVariants); err != nil {
+		return nil, err
+	}
 	return &user, nil
 }
 
@@ -88,10 +96,11 @@ func (r *userRepo) UpdateProfile(user *model.User) error {
     location = $3,
     avatar_url = $4,
     social_links = $5,
+    sensitive_media = $6,
     updated_at = NOW()
-  WHERE id = $6
+  WHERE id = $7
 `,
-		user.Name, user.Bio, user.Location, user.AvatarURL, user.SocialLinks, user.ID,
+		user.Name, user.Bio, user.Location, user.AvatarURL, user.SocialLinks, user.SensitiveMedia, user.ID,
 	)
 
 	return err
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStoragePut(t *testing.T) {
+	dir := t.TempDir()
+	s := NewLocalStorage(dir, "http://localhost:8080/")
+	url, err := s.Put(context.Background(), "avatars/u/a.png", strings.NewReader("image bytes and more"), 11, "image/png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "http://localhost:8080/media/avatars/u/a.png" {
+		t.Errorf("url = %q", url)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "avatars", "u", "a.png"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "image bytes" {
+		t.Errorf("stored %q, want the declared size only", data)
+	}
+	leftovers, _ := filepath.Glob(filepath.Join(dir, "avatars", "u", ".upload-*"))
+	if len(leftovers) != 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+
+	if err := s.Delete(context.Background(), "avatars/u/a.png"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "avatars", "u", "a.png")); !os.IsNotExist(err) {
+		t.Errorf("file still exists: %v", err)
+	}
+	if err := s.Delete(context.Background(), "avatars/u/a.png"); err != nil {
+		t.Errorf("deleting a missing file: %v", err)
+	}
+}
+
+func TestLocalStorageRejectsKeys(t *testing.T) {
+	dir := t.TempDir()
+	s := NewLocalStorage(filepath.Join(dir, "media"), "")
+	for _, key := range []string{"", "/", "../outside.png", "a/../../outside.png"} {
+		if _, err := s.Put(context.Background(), key, strings.NewReader("x"), 1, "image/png"); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) = %v, want ErrInvalidKey", key, err)
+		}
+		if err := s.Delete(context.Background(), key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Delete(%q) = %v, want ErrInvalidKey", key, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "outside.png")); !os.IsNotExist(err) {
+		t.Error("a file was written outside the media directory")
+	}
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"WebMessanger/internal/usecase"
+	"errors"
+	"fmt"
+	"os"
+)
+
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// FromEnv builds the storage selected by STORAGE_DRIVER: "local" (the
+// default) writes under MEDIA_DIR, "s3" uploads to the S3_* bucket.
+func FromEnv() (usecase.Storage, error) {
+	switch driver := os.Getenv("STORAGE_DRIVER"); driver {
+	case "", "local":
+		dir := os.Getenv("MEDIA_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		return NewLocalStorage(dir, os.Getenv("SERVER_URL")), nil
+	case "s3":
+		region := os.Getenv("S3_REGION")
+		if region == "" {
+			region = "us-east-1"
+		}
+		return NewS3Storage(
+			os.Getenv("S3_ENDPOINT"),
+			region,
+			os.Getenv("S3_BUCKET"),
+			os.Getenv("S3_ACCESS_KEY_ID"),
+			os.Getenv("S3_SECRET_ACCESS_KEY"),
+			os.Getenv("S3_PUBLIC_URL"),
+		)
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_DRIVER %q", driver)
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/google/uuid"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrTooManyAttachments    = errors.New("a thread can have up to four images or a single video")
+	ErrInvalidAttachment     = errors.New("each attachment needs a type of image or video and a url")
+	ErrAltTextRequired       = errors.New("alt text is required for images")
+	ErrAltTextTooLong        = errors.New("alt text is too long")
+	ErrMixedAttachmentTypes  = errors.New("images and videos cannot be attached together")
+	ErrUnknownAttachmentType = errors.New("attachment type does not match the uploaded file")
+	ErrAttachmentURL         = errors.New("attachment url must be an http or https URL or one of your uploads")
+)
+
+// AttachmentValidator checks and completes thread attachments before they
+// are stored.
+type AttachmentValidator struct {
+	media          usecase.MediaRepository
+	requireAltText bool
+}
+
+func NewAttachmentValidator(media usecase.MediaRepository, requireAltText bool) *AttachmentValidator {
+	return &AttachmentValidator{media: media, requireAltText: requireAltText}
+}
+
+// RequireAltTextFromEnv reads REQUIRE_ALT_TEXT; alt text is optional unless
+// it is set to true.
+func RequireAltTextFromEnv() bool {
+	return os.Getenv("REQUIRE_ALT_TEXT") == "true"
+}
+
+// Prepare normalizes thread.Attachments in place. A request from an old
+// client that only sets media_url gets a single attachment built from it.
+// Type and dimensions of files uploaded through the media endpoints are taken
+// from the upload record rather than trusted from the client. A new
+// attachment must be one of the author's own uploads or an http(s) URL.
+//
+// previous holds the attachments already stored for the thread when it is
+// edited. An attachment with the same URL as one of them keeps its ID, and
+// the alt text rule only applies to newly added ones so that an edit doesn't
+// fail over images posted before the rule was turned on.
+func (v *AttachmentValidator) Prepare(thread *model.Thread, previous []*model.Attachment) error {
+	if len(thread.Attachments) == 0 && thread.MediaURL != "" {
+		thread.Attachments = []*model.Attachment{{URL: thread.MediaURL}}
+	}
+	kept := make(map[uuid.UUID]bool)
+	match := func(a *model.Attachment) *model.Attachment {
+		for _, p := range previous {
+			if !kept[p.ID] && a.ID == p.ID && a.URL == p.URL {
+				return p
+			}
+		}
+		for _, p := range previous {
+			if !kept[p.ID] && a.URL == p.URL {
+				return p
+			}
+		}
+		return nil
+	}
+	var images, videos int
+	for i, a := range thread.Attachments {
+		if a == nil || strings.TrimSpace(a.URL) == "" {
+			return ErrInvalidAttachment
+		}
+		a.URL = strings.TrimSpace(a.URL)
+		a.AltText = strings.TrimSpace(a.AltText)
+		existing := match(a)
+		a.ID = uuid.New()
+		if existing != nil {
+			a.ID = existing.ID
+			kept[a.ID] = true
+		}
+		upload, err := v.media.GetByURL(a.URL)
+		if existing == nil && (err == nil && upload.UserID != thread.UserID || err != nil && !validURL(a.URL, false)) {
+			return ErrAttachmentURL
+		}
+		if err == nil {
+			uploadType := model.AttachmentImage
+			if strings.HasPrefix(upload.ContentType, "video/") {
+				uploadType = model.AttachmentVideo
+			}
+			if a.Type != "" && a.Type != uploadType {
+				return ErrUnknownAttachmentType
+			}
+			a.Type = uploadType
+			a.Width, a.Height = upload.Width, upload.Height
+		} else if a.Type == "" {
+			a.Type = model.AttachmentImage
+		}
+
+		switch a.Type {
+		case model.AttachmentImage:
+			images++
+			if v.requireAltText && a.AltText == "" && existing == nil {
+				return ErrAltTextRequired
+			}
+		case model.AttachmentVideo:
+			videos++
+		default:
+			return ErrInvalidAttachment
+		}
+		if utf8.RuneCountInString(a.AltText) > model.MaxAltTextLength {
+			return ErrAltTextTooLong
+		}
+		if (a.Width == nil) != (a.Height == nil) || (a.Width != nil && (*a.Width <= 0 || *a.Height <= 0)) {
+			a.Width, a.Height = nil, nil
+		}
+		a.Position = i
+		a.Variants = nil
+	}
+	if images > 0 && videos > 0 {
+		return ErrMixedAttachmentTypes
+	}
+	if images > model.MaxImageAttachments || videos > model.MaxVideoAttachments {
+		return ErrTooManyAttachments
+	}
+	thread.MediaURL = ""
+	if len(thread.Attachments) > 0 {
+		thread.MediaURL = thread.Attachments[0].URL
+	}
+	return nil
+}
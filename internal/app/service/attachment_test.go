@@ -0,0 +1,154 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"errors"
+	"github.com/google/uuid"
+	"reflect"
+	"testing"
+)
+
+// fakeMediaRepo serves GetByURL from a fixed set of uploads.
+type fakeMediaRepo struct {
+	uploads map[string]*model.Media
+}
+
+func (fakeMediaRepo) Save(uuid.UUID, *model.Media) error { return nil }
+
+func (r fakeMediaRepo) GetByURL(url string) (*model.Media, error) {
+	if m, ok := r.uploads[url]; ok {
+		return m, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func (fakeMediaRepo) Delete(string) error { return nil }
+
+func TestAttachmentPrepareKeepsExisting(t *testing.T) {
+	author := uuid.New()
+	media := fakeMediaRepo{uploads: map[string]*model.Media{
+		"/uploads/new.png": {URL: "/uploads/new.png", ContentType: "image/jpeg", UserID: author},
+		"/uploads/old.png": {URL: "/uploads/old.png", ContentType: "image/jpeg", UserID: author},
+	}}
+	oldID := uuid.New()
+	previous := []*model.Attachment{{ID: oldID, Type: model.AttachmentImage, URL: "/uploads/old.png"}}
+	tests := []struct {
+		name        string
+		attachments []*model.Attachment
+		previous    []*model.Attachment
+		wantErr     error
+		keepsID     []bool
+	}{
+		{
+			name:        "new attachment needs alt text",
+			attachments: []*model.Attachment{{URL: "/uploads/new.png"}},
+			wantErr:     ErrAltTextRequired,
+		},
+		{
+			name:        "new attachment with alt text",
+			attachments: []*model.Attachment{{URL: "/uploads/new.png", AltText: "a cat"}},
+			keepsID:     []bool{false},
+		},
+		{
+			name:        "existing attachment keeps its id without alt text",
+			attachments: []*model.Attachment{{ID: oldID, URL: "/uploads/old.png"}},
+			previous:    previous,
+			keepsID:     []bool{true},
+		},
+		{
+			name:        "existing attachment matched by url",
+			attachments: []*model.Attachment{{URL: " /uploads/old.png "}},
+			previous:    previous,
+			keepsID:     []bool{true},
+		},
+		{
+			name:        "id reused for another file is a new attachment",
+			attachments: []*model.Attachment{{ID: oldID, URL: "/uploads/new.png"}},
+			previous:    previous,
+			wantErr:     ErrAltTextRequired,
+		},
+		{
+			name:        "existing kept and new added",
+			attachments: []*model.Attachment{{URL: "/uploads/old.png"}, {URL: "/uploads/new.png", AltText: "a dog"}},
+			previous:    previous,
+			keepsID:     []bool{true, false},
+		},
+		{
+			name:        "duplicate url only keeps one id",
+			attachments: []*model.Attachment{{URL: "/uploads/old.png"}, {URL: "/uploads/old.png", AltText: "again"}},
+			previous:    previous,
+			keepsID:     []bool{true, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewAttachmentValidator(media, true)
+			thread := &model.Thread{UserID: author, Attachments: tt.attachments}
+			err := v.Prepare(thread, tt.previous)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for i, keep := range tt.keepsID {
+				a := thread.Attachments[i]
+				if a.ID == uuid.Nil {
+					t.Errorf("attachment %d has no id", i)
+				}
+				if (a.ID == oldID) != keep {
+					t.Errorf("attachment %d: kept id = %v, want %v", i, a.ID == oldID, keep)
+				}
+				if a.Position != i {
+					t.Errorf("attachment %d: position = %d", i, a.Position)
+				}
+			}
+		})
+	}
+}
+
+func TestAttachmentPrepareChecksURL(t *testing.T) {
+	author, other := uuid.New(), uuid.New()
+	media := fakeMediaRepo{uploads: map[string]*model.Media{
+		"/media/threads/mine.jpg":            {ContentType: "image/jpeg", UserID: author},
+		"/media/threads/theirs.jpg":          {ContentType: "image/jpeg", UserID: other},
+		"https://cdn.example.com/theirs.jpg": {ContentType: "image/jpeg", UserID: other},
+	}}
+	previous := []*model.Attachment{{ID: uuid.New(), Type: model.AttachmentImage, URL: "/media/threads/theirs.jpg"}}
+	tests := []struct {
+		name     string
+		url      string
+		previous []*model.Attachment
+		wantErr  error
+	}{
+		{"own upload", "/media/threads/mine.jpg", nil, nil},
+		{"external https", "https://example.com/cat.png", nil, nil},
+		{"external http", "http://example.com/cat.png", nil, nil},
+		{"someone else's upload", "/media/threads/theirs.jpg", nil, ErrAttachmentURL},
+		{"someone else's upload by absolute url", "https://cdn.example.com/theirs.jpg", nil, ErrAttachmentURL},
+		{"path that was never uploaded", "/media/threads/unknown.jpg", nil, ErrAttachmentURL},
+		{"javascript", "javascript:alert(1)", nil, ErrAttachmentURL},
+		{"data", "data:image/png;base64,AAAA", nil, ErrAttachmentURL},
+		{"protocol-relative", "//evil.example/a.png", nil, ErrAttachmentURL},
+		{"no host", "https:///a.png", nil, ErrAttachmentURL},
+		{"kept on edit", "/media/threads/theirs.jpg", previous, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewAttachmentValidator(media, false)
+			thread := &model.Thread{UserID: author, Attachments: []*model.Attachment{{URL: tt.url}}}
+			if err := v.Prepare(thread, tt.previous); !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckAttachmentsReportsField(t *testing.T) {
+	var v validator
+	checkAttachments(&v, ErrAttachmentURL)
+	want := map[string]string{"attachments": CodeInvalidURL}
+	if got := fieldCodes(t, v.err()); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/google/uuid"
+)
+
+var ErrBlockSelf = errors.New("you cannot block yourself")
+
+type BlockService struct {
+	repo     usecase.BlockRepository
+	userRepo usecase.UserRepository
+}
+
+func NewBlockService(repo usecase.BlockRepository, userRepo usecase.UserRepository) *BlockService {
+	return &BlockService{repo: repo, userRepo: userRepo}
+}
+func (s *BlockService) Block(blockerID, blockedID uuid.UUID) error {
+	if blockerID == blockedID {
+		return ErrBlockSelf
+	}
+	if _, err := s.userRepo.GetByID(blockedID); err != nil {
+		return ErrUserNotFound
+	}
+	return s.repo.Block(blockerID, blockedID)
+}
+func (s *BlockService) Unblock(blockerID, blockedID uuid.UUID) error {
+	return s.repo.Unblock(blockerID, blockedID)
+}
+func (s *BlockService) GetBlocked(userID uuid.UUID) ([]*model.PublicUser, error) {
+	return s.repo.GetBlocked(userID)
+}
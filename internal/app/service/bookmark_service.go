@@ -0,0 +1,88 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+const maxBookmarkFolderLength = 50
+
+var (
+	ErrInvalidCursor     = errors.New("invalid cursor")
+	ErrInvalidFolderName = errors.New("folder name is too long")
+)
+
+type BookmarkService struct {
+	repo       usecase.BookmarkRepository
+	threadRepo usecase.ThreadRepository
+}
+
+func NewBookmarkService(repo usecase.BookmarkRepository, threadRepo usecase.ThreadRepository) *BookmarkService {
+	return &BookmarkService{repo: repo, threadRepo: threadRepo}
+}
+func (s *BookmarkService) Add(bookmark *model.Bookmark) (*model.Bookmark, error) {
+	bookmark.Folder = strings.TrimSpace(bookmark.Folder)
+	if len([]rune(bookmark.Folder)) > maxBookmarkFolderLength {
+		return nil, ErrInvalidFolderName
+	}
+	_, err := s.threadRepo.GetThreadById(bookmark.ThreadID, bookmark.UserID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrThreadNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	bookmark.ID = uuid.New()
+	bookmark.CreatedAt = time.Now()
+	if err := s.repo.Add(bookmark); err != nil {
+		return nil, err
+	}
+	return bookmark, nil
+}
+func (s *BookmarkService) Remove(threadID, userID uuid.UUID) error {
+	return s.repo.Remove(threadID, userID)
+}
+
+// List returns a page of the user's bookmarks, newest first, and the cursor
+// for the next page ("" when there are no more).
+func (s *BookmarkService) List(userID uuid.UUID, folder *string, cursor string, limit int) ([]*model.Bookmark, string, error) {
+	after, err := decodeBookmarkCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if folder != nil {
+		trimmed := strings.TrimSpace(*folder)
+		folder = &trimmed
+	}
+	limit = normalizePageSize(limit)
+	bookmarks, err := s.repo.List(userID, folder, after, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(bookmarks) <= limit {
+		return bookmarks, "", nil
+	}
+	bookmarks = bookmarks[:limit]
+	last := bookmarks[len(bookmarks)-1]
+	return bookmarks, encodeBookmarkCursor(&model.BookmarkCursor{CreatedAt: last.CreatedAt, ID: last.ID}), nil
+}
+func (s *BookmarkService) GetFolders(userID uuid.UUID) ([]*model.BookmarkFolder, error) {
+	return s.repo.GetFolders(userID)
+}
+
+func encodeBookmarkCursor(c *model.BookmarkCursor) string {
+	return encodeCursor(c.CreatedAt, c.ID)
+}
+
+func decodeBookmarkCursor(cursor string) (*model.BookmarkCursor, error) {
+	createdAt, id, ok, err := decodeCursor(cursor)
+	if !ok || err != nil {
+		return nil, err
+	}
+	return &model.BookmarkCursor{CreatedAt: createdAt, ID: id}, nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"testing"
+)
+
+// fakeBookmarkRepo records added bookmarks. Other methods are not expected
+// to be called.
+type fakeBookmarkRepo struct {
+	usecase.BookmarkRepository
+	added []*model.Bookmark
+}
+
+func (r *fakeBookmarkRepo) Add(bookmark *model.Bookmark) error {
+	r.added = append(r.added, bookmark)
+	return nil
+}
+
+func TestBookmarkAddThreadLookup(t *testing.T) {
+	dbErr := errors.New("connection refused")
+	tests := []struct {
+		name    string
+		repoErr error
+		wantErr error
+	}{
+		{"visible thread", nil, nil},
+		{"missing or hidden thread", sql.ErrNoRows, ErrThreadNotFound},
+		{"database failure", dbErr, dbErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeBookmarkRepo{}
+			threads := &fakeThreadRepo{thread: &model.Thread{ID: uuid.New()}, err: tt.repoErr}
+			s := NewBookmarkService(repo, threads)
+			_, err := s.Add(&model.Bookmark{UserID: uuid.New(), ThreadID: threads.thread.ID})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if added := len(repo.added) == 1; added != (tt.wantErr == nil) {
+				t.Errorf("bookmark stored = %v", added)
+			}
+		})
+	}
+}
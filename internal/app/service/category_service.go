@@ -0,0 +1,111 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+var (
+	ErrCategoryNotFound  = errors.New("category not found")
+	ErrCategorySlugTaken = errors.New("a category with this slug already exists")
+	ErrNotAdmin          = errors.New("only admins can do this")
+)
+
+// CategoryService manages the category taxonomy. Anyone may browse it; only
+// admins may change it.
+type CategoryService struct {
+	repo       usecase.CategoryRepository
+	threadRepo usecase.ThreadRepository
+	users      usecase.UserRepository
+}
+
+func NewCategoryService(repo usecase.CategoryRepository, threadRepo usecase.ThreadRepository, users usecase.UserRepository) *CategoryService {
+	return &CategoryService{repo: repo, threadRepo: threadRepo, users: users}
+}
+
+// List returns every category with its thread count.
+func (s *CategoryService) List() ([]*model.Category, error) {
+	return s.repo.List()
+}
+
+func (s *CategoryService) Create(actorID uuid.UUID, category *model.Category) (*model.Category, error) {
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+	if err := ValidateCategory(category); err != nil {
+		return nil, err
+	}
+	if existing, _ := s.repo.GetBySlug(category.Slug); existing != nil {
+		return nil, ErrCategorySlugTaken
+	}
+	category.ID = uuid.New()
+	category.CreatedAt = time.Now()
+	if err := s.repo.Create(category); err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// Update replaces the name, slug, description and position of a category.
+func (s *CategoryService) Update(actorID uuid.UUID, category *model.Category) (*model.Category, error) {
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.GetByID(category.ID)
+	if err != nil {
+		return nil, ErrCategoryNotFound
+	}
+	if err := ValidateCategory(category); err != nil {
+		return nil, err
+	}
+	if other, _ := s.repo.GetBySlug(category.Slug); other != nil && other.ID != category.ID {
+		return nil, ErrCategorySlugTaken
+	}
+	if err := s.repo.Update(category); err != nil {
+		return nil, err
+	}
+	category.ThreadCount = existing.ThreadCount
+	category.CreatedAt = existing.CreatedAt
+	return category, nil
+}
+
+// Delete removes a category. Its threads are kept without a category.
+func (s *CategoryService) Delete(actorID, id uuid.UUID) error {
+	if err := s.requireAdmin(actorID); err != nil {
+		return err
+	}
+	if _, err := s.repo.GetByID(id); err != nil {
+		return ErrCategoryNotFound
+	}
+	return s.repo.Delete(id)
+}
+
+// GetThreads returns the category and a page of its threads.
+func (s *CategoryService) GetThreads(id, viewerID uuid.UUID, limit, offset int) (*model.Category, []*model.Thread, error) {
+	category, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, nil, ErrCategoryNotFound
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	threads, err := s.threadRepo.GetByCategory(id, viewerID, normalizePageSize(limit), offset)
+	if err != nil {
+		return nil, nil, err
+	}
+	return category, threads, nil
+}
+
+func (s *CategoryService) requireAdmin(actorID uuid.UUID) error {
+	actor, err := s.users.GetByID(actorID)
+	if err != nil {
+		return err
+	}
+	if !actor.IsAdmin() {
+		return ErrNotAdmin
+	}
+	return nil
+}
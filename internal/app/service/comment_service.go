@@ -3,29 +3,283 @@ package service
 import (
 	"WebMessanger/internal/model"
 	"WebMessanger/internal/usecase"
+	"errors"
 	"github.com/google/uuid"
 	"time"
 )
 
+const (
+	DefaultCommentMaxDepth  = 5
+	MaxCommentDepth         = 10
+	DefaultRepliesPerBranch = 10
+	MaxRepliesPerBranch     = 100
+)
+
+var (
+	ErrParentCommentNotFound = errors.New("parent comment not found in this thread")
+	ErrCommentNotFound       = errors.New("comment not found")
+	ErrNotCommentOwner       = errors.New("you can only change your own comments")
+)
+
 type CommentService struct {
-	repo usecase.CommentRepository
+	repo       usecase.CommentRepository
+	mentions   *MentionService
+	access     *ThreadAccess
+	moderation *ModerationService
 }
 
-func NewCommentService(repo usecase.CommentRepository) *CommentService {
-	return &CommentService{repo: repo}
+func NewCommentService(repo usecase.CommentRepository, mentions *MentionService, access *ThreadAccess, moderation *ModerationService) *CommentService {
+	return &CommentService{repo: repo, mentions: mentions, access: access, moderation: moderation}
 }
 func (s *CommentService) Create(comment *model.Comment) (*model.Comment, error) {
+	if err := ValidateComment(comment); err != nil {
+		return nil, err
+	}
+	thread, err := s.access.Visible(comment.ThreadID, comment.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.access.CheckReply(thread, comment.UserID); err != nil {
+		return nil, err
+	}
+	if comment.ParentID != nil {
+		parent, err := s.repo.GetByID(*comment.ParentID)
+		if err != nil || parent.IsDeleted || parent.ThreadID != comment.ThreadID {
+			return nil, ErrParentCommentNotFound
+		}
+	}
 	comment.ID = uuid.New()
 	comment.CreatedAt = time.Now()
+	comment.ContentHTML = RenderMarkdown(comment.Content)
 	createdComment, err := s.repo.Create(comment)
 	if err != nil {
 		return nil, err
 	}
+	createdComment.Mentions, err = s.mentions.Index(model.MentionSourceComment, createdComment.ID, createdComment.ThreadID, createdComment.UserID, createdComment.Content, true)
+	if err != nil {
+		return nil, err
+	}
 	return createdComment, nil
 }
-func (s *CommentService) Delete(id uuid.UUID) error {
-	return s.repo.Delete(id)
+
+// Update edits the content of the caller's comment. Comments in locked
+// threads cannot be edited.
+func (s *CommentService) Update(id, userID uuid.UUID, content string) (*model.Comment, error) {
+	comment, err := s.repo.GetByID(id)
+	if err != nil || comment.IsDeleted {
+		return nil, ErrCommentNotFound
+	}
+	if comment.UserID != userID {
+		return nil, ErrNotCommentOwner
+	}
+	thread, err := s.access.Visible(comment.ThreadID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if thread.Locked {
+		return nil, ErrThreadLocked
+	}
+	comment.Content = content
+	if err := ValidateComment(comment); err != nil {
+		return nil, err
+	}
+	comment.ContentHTML = RenderMarkdown(comment.Content)
+	if err := s.repo.Update(comment); err != nil {
+		return nil, err
+	}
+	comment.Mentions, err = s.mentions.Index(model.MentionSourceComment, comment.ID, comment.ThreadID, comment.UserID, comment.Content, true)
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// RenderMissingHTML renders content_html for up to limit comments that were
+// stored before Markdown rendering existed and reports how many it handled.
+func (s *CommentService) RenderMissingHTML(limit int) (int, error) {
+	comments, err := s.repo.GetUnrendered(limit)
+	if err != nil {
+		return 0, err
+	}
+	for _, c := range comments {
+		if err := s.repo.SetContentHTML(c.ID, RenderMarkdown(c.Content)); err != nil {
+			return 0, err
+		}
+	}
+	return len(comments), nil
 }
-func (s *CommentService) GetByThread(threadID uuid.UUID) ([]*model.Comment, error) {
-	return s.repo.GetByThread(threadID)
+
+// Delete soft-deletes a comment on behalf of its author or a moderator of the
+// thread. Readers see a "[deleted]" placeholder while it still has replies;
+// the author can restore it within model.RestoreWindow.
+func (s *CommentService) Delete(id, actorID uuid.UUID) error {
+	comment, err := s.repo.GetByID(id)
+	if err != nil || comment.IsDeleted {
+		return ErrCommentNotFound
+	}
+	if err := s.moderation.CanRemoveComment(comment.ThreadID, comment.UserID, actorID); err != nil {
+		return err
+	}
+	return s.repo.Delete(id, actorID)
+}
+
+// GetDeleted lists the comments the user deleted and can still restore.
+func (s *CommentService) GetDeleted(userID uuid.UUID) ([]*model.Comment, error) {
+	return s.repo.GetDeletedByUser(userID, time.Now().Add(-model.RestoreWindow))
+}
+
+// Restore brings back a comment the user deleted themselves, as long as its
+// thread is still visible to them.
+func (s *CommentService) Restore(id, userID uuid.UUID) (*model.Comment, error) {
+	comment, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, ErrCommentNotFound
+	}
+	if _, err := s.access.Visible(comment.ThreadID, userID); err != nil {
+		return nil, err
+	}
+	restored, err := s.repo.Restore(id, userID, time.Now().Add(-model.RestoreWindow))
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, ErrCommentNotFound
+	}
+	return s.repo.GetByID(id)
+}
+
+// PurgeDeleted permanently removes comments whose restore window closed by
+// now, batchSize at a time, and reports how many it handled in this batch.
+func (s *CommentService) PurgeDeleted(now time.Time, batchSize int) (int, error) {
+	return s.repo.PurgeDeleted(now.Add(-model.RestoreWindow), batchSize)
+}
+func (s *CommentService) GetByID(id uuid.UUID) (*model.Comment, error) {
+	return s.repo.GetByID(id)
+}
+func (s *CommentService) GetByThread(threadID, viewerID uuid.UUID, maxDepth, repliesLimit int) ([]*model.Comment, error) {
+	if _, err := s.access.Visible(threadID, viewerID); err != nil {
+		return nil, err
+	}
+	comments, err := s.repo.GetByThread(threadID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	roots, _ := buildCommentTree(comments)
+	pruneCommentTree(roots, normalizeDepth(maxDepth), normalizeRepliesLimit(repliesLimit))
+	return roots, nil
+}
+
+// GetReplies returns one page of direct replies to a comment together with
+// their own subtrees, and reports whether more replies remain in that branch.
+func (s *CommentService) GetReplies(threadID, commentID, viewerID uuid.UUID, offset, limit, maxDepth int) ([]*model.Comment, bool, error) {
+	parent, err := s.repo.GetByID(commentID)
+	if err != nil || parent.ThreadID != threadID {
+		return nil, false, ErrCommentNotFound
+	}
+	if _, err := s.access.Visible(parent.ThreadID, viewerID); err != nil {
+		return nil, false, err
+	}
+	comments, err := s.repo.GetByThread(parent.ThreadID, viewerID)
+	if err != nil {
+		return nil, false, err
+	}
+	_, nodes := buildCommentTree(comments)
+	node, ok := nodes[commentID]
+	if !ok {
+		return []*model.Comment{}, false, nil
+	}
+	limit = normalizeRepliesLimit(limit)
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(node.Replies) {
+		return []*model.Comment{}, false, nil
+	}
+	end := offset + limit
+	if end > len(node.Replies) {
+		end = len(node.Replies)
+	}
+	page := node.Replies[offset:end]
+	pruneCommentTree(page, normalizeDepth(maxDepth), limit)
+	return page, end < len(node.Replies), nil
+}
+
+func buildCommentTree(comments []*model.Comment) ([]*model.Comment, map[uuid.UUID]*model.Comment) {
+	nodes := make(map[uuid.UUID]*model.Comment, len(comments))
+	for _, c := range comments {
+		nodes[c.ID] = c
+	}
+	roots := make([]*model.Comment, 0)
+	for _, c := range comments {
+		if c.ParentID != nil {
+			if parent, ok := nodes[*c.ParentID]; ok {
+				parent.Replies = append(parent.Replies, c)
+				parent.ReplyCount++
+				continue
+			}
+		}
+		roots = append(roots, c)
+	}
+	roots = dropDeletedLeaves(roots)
+	setCommentDepth(roots, 0)
+	return roots, nodes
+}
+
+// dropDeletedLeaves removes deleted comments that have no remaining replies,
+// so placeholders only show where they hold a conversation together.
+func dropDeletedLeaves(comments []*model.Comment) []*model.Comment {
+	kept := comments[:0]
+	for _, c := range comments {
+		c.Replies = dropDeletedLeaves(c.Replies)
+		c.ReplyCount = len(c.Replies)
+		if c.IsDeleted && len(c.Replies) == 0 {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	return kept
+}
+
+func setCommentDepth(comments []*model.Comment, depth int) {
+	for _, c := range comments {
+		c.Depth = depth
+		setCommentDepth(c.Replies, depth+1)
+	}
+}
+
+// pruneCommentTree cuts every branch to the given number of reply levels and
+// replies per level, flagging the nodes that have more to load.
+func pruneCommentTree(comments []*model.Comment, levels, repliesLimit int) {
+	for _, c := range comments {
+		if levels <= 0 {
+			c.HasMoreReplies = len(c.Replies) > 0
+			c.Replies = nil
+			continue
+		}
+		if len(c.Replies) > repliesLimit {
+			c.Replies = c.Replies[:repliesLimit]
+			c.HasMoreReplies = true
+		}
+		pruneCommentTree(c.Replies, levels-1, repliesLimit)
+	}
+}
+
+func normalizeDepth(depth int) int {
+	if depth <= 0 {
+		return DefaultCommentMaxDepth
+	}
+	if depth > MaxCommentDepth {
+		return MaxCommentDepth
+	}
+	return depth
+}
+
+func normalizeRepliesLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultRepliesPerBranch
+	}
+	if limit > MaxRepliesPerBranch {
+		return MaxRepliesPerBranch
+	}
+	return limit
 }
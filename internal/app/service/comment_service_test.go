@@ -0,0 +1,197 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"testing"
+)
+
+// commentFixture builds comments from "name:parent" specs, where an empty
+// parent makes a root and a trailing "!" marks the comment deleted. IDs are
+// derived from the names so tests can refer to them.
+func commentFixture(specs ...string) []*model.Comment {
+	var comments []*model.Comment
+	for _, spec := range specs {
+		spec, deleted := strings.CutSuffix(spec, "!")
+		name, parent, _ := strings.Cut(spec, ":")
+		c := &model.Comment{ID: commentID(name), Content: name, IsDeleted: deleted}
+		if parent != "" {
+			id := commentID(parent)
+			c.ParentID = &id
+		}
+		comments = append(comments, c)
+	}
+	return comments
+}
+
+func commentID(name string) uuid.UUID {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(name))
+}
+
+// shape renders a tree as nested names with depth, reply count and a "+"
+// when more replies can be loaded.
+func shape(comments []*model.Comment) string {
+	s := ""
+	for i, c := range comments {
+		if i > 0 {
+			s += " "
+		}
+		s += fmt.Sprintf("%s/%d/%d", c.Content, c.Depth, c.ReplyCount)
+		if c.HasMoreReplies {
+			s += "+"
+		}
+		if len(c.Replies) > 0 {
+			s += "(" + shape(c.Replies) + ")"
+		}
+	}
+	return s
+}
+
+func TestBuildCommentTree(t *testing.T) {
+	tests := []struct {
+		name     string
+		comments []*model.Comment
+		want     string
+	}{
+		{"empty", nil, ""},
+		{"flat", commentFixture("a", "b"), "a/0/0 b/0/0"},
+		{"nested", commentFixture("a", "b:a", "c:b", "d:a"), "a/0/2(b/1/1(c/2/0) d/1/0)"},
+		{"orphan becomes root", commentFixture("a", "b:missing"), "a/0/0 b/0/0"},
+		{"deleted leaf dropped", commentFixture("a", "b:a!", "c"), "a/0/0 c/0/0"},
+		{"deleted root with replies kept", commentFixture("a!", "b:a"), "a/0/1(b/1/0)"},
+		{"deleted chain dropped", commentFixture("a!", "b:a!", "c:b!"), ""},
+		{"deleted parent of deleted leaf dropped", commentFixture("a", "b:a!", "c:b!"), "a/0/0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roots, nodes := buildCommentTree(tt.comments)
+			if got := shape(roots); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+			if len(nodes) != len(tt.comments) {
+				t.Errorf("nodes has %d entries, want %d", len(nodes), len(tt.comments))
+			}
+		})
+	}
+}
+
+func TestPruneCommentTree(t *testing.T) {
+	deep := commentFixture("a", "b:a", "c:b", "d:c")
+	wide := commentFixture("a", "b:a", "c:a", "d:a", "e:b", "f:b")
+	tests := []struct {
+		name     string
+		comments []*model.Comment
+		levels   int
+		limit    int
+		want     string
+	}{
+		{"no pruning", deep, 10, 10, "a/0/1(b/1/1(c/2/1(d/3/0)))"},
+		{"depth limit", deep, 2, 10, "a/0/1(b/1/1(c/2/1+))"},
+		{"roots only", deep, 0, 10, "a/0/1+"},
+		{"leaf at limit has nothing more", deep, 3, 10, "a/0/1(b/1/1(c/2/1(d/3/0)))"},
+		{"replies limit", wide, 10, 2, "a/0/3+(b/1/2(e/2/0 f/2/0) c/1/0)"},
+		{"both limits", wide, 1, 1, "a/0/3+(b/1/2+)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, c := range tt.comments {
+				c.Replies, c.ReplyCount, c.HasMoreReplies = nil, 0, false
+			}
+			roots, _ := buildCommentTree(tt.comments)
+			pruneCommentTree(roots, tt.levels, tt.limit)
+			if got := shape(roots); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeCommentLimits(t *testing.T) {
+	tests := []struct {
+		in, depth, replies int
+	}{
+		{-1, DefaultCommentMaxDepth, DefaultRepliesPerBranch},
+		{0, DefaultCommentMaxDepth, DefaultRepliesPerBranch},
+		{3, 3, 3},
+		{MaxCommentDepth + 1, MaxCommentDepth, MaxCommentDepth + 1},
+		{MaxRepliesPerBranch + 1, MaxCommentDepth, MaxRepliesPerBranch},
+	}
+	for _, tt := range tests {
+		if got := normalizeDepth(tt.in); got != tt.depth {
+			t.Errorf("normalizeDepth(%d) = %d, want %d", tt.in, got, tt.depth)
+		}
+		if got := normalizeRepliesLimit(tt.in); got != tt.replies {
+			t.Errorf("normalizeRepliesLimit(%d) = %d, want %d", tt.in, got, tt.replies)
+		}
+	}
+}
+
+// fakeCommentRepo serves GetByID from a fixed set of comments. Other methods
+// are not expected to be called.
+type fakeCommentRepo struct {
+	usecase.CommentRepository
+	comments map[uuid.UUID]*model.Comment
+}
+
+func (r *fakeCommentRepo) GetByID(id uuid.UUID) (*model.Comment, error) {
+	if c, ok := r.comments[id]; ok {
+		return c, nil
+	}
+	return nil, errors.New("no rows")
+}
+
+func TestGetRepliesChecksThread(t *testing.T) {
+	threadID, otherThreadID := uuid.New(), uuid.New()
+	parent := &model.Comment{ID: uuid.New(), ThreadID: otherThreadID}
+	s := NewCommentService(&fakeCommentRepo{comments: map[uuid.UUID]*model.Comment{parent.ID: parent}}, nil, nil, nil)
+
+	tests := []struct {
+		name      string
+		commentID uuid.UUID
+	}{
+		{"comment of another thread", parent.ID},
+		{"unknown comment", uuid.New()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replies, hasMore, err := s.GetReplies(threadID, tt.commentID, uuid.New(), 0, 0, 0)
+			if !errors.Is(err, ErrCommentNotFound) {
+				t.Errorf("got %v, want ErrCommentNotFound", err)
+			}
+			if replies != nil || hasMore {
+				t.Errorf("got replies %v, has more %v", replies, hasMore)
+			}
+		})
+	}
+}
+
+func TestCreateReplyChecksParent(t *testing.T) {
+	threadID, otherThreadID := uuid.New(), uuid.New()
+	live := &model.Comment{ID: uuid.New(), ThreadID: threadID}
+	deleted := &model.Comment{ID: uuid.New(), ThreadID: threadID, IsDeleted: true}
+	elsewhere := &model.Comment{ID: uuid.New(), ThreadID: otherThreadID}
+	repo := &fakeCommentRepo{comments: map[uuid.UUID]*model.Comment{live.ID: live, deleted.ID: deleted, elsewhere.ID: elsewhere}}
+	threads := &fakeThreadRepo{thread: &model.Thread{ID: threadID, UserID: uuid.New(), Status: model.ThreadStatusPublished}}
+	s := NewCommentService(repo, nil, NewThreadAccess(threads, nil), nil)
+
+	tests := []struct {
+		name     string
+		parentID uuid.UUID
+	}{
+		{"deleted parent", deleted.ID},
+		{"parent in another thread", elsewhere.ID},
+		{"unknown parent", uuid.New()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			comment := &model.Comment{ThreadID: threadID, UserID: uuid.New(), ParentID: &tt.parentID, Content: "reply"}
+			if _, err := s.Create(comment); !errors.Is(err, ErrParentCommentNotFound) {
+				t.Errorf("got %v, want ErrParentCommentNotFound", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+var (
+	ErrCommunityNotFound     = errors.New("community not found")
+	ErrCommunityNameTaken    = errors.New("a community with this name already exists")
+	ErrNotCommunityMember    = errors.New("you must join the community first")
+	ErrNotCommunityModerator = errors.New("only the community's moderators can do this")
+	ErrNotCommunityOwner     = errors.New("only the community's owner can do this")
+	ErrCommunityOwner        = errors.New("the owner cannot leave or be removed from the community")
+	ErrMemberNotFound        = errors.New("user is not a member of this community")
+	ErrBannedFromCommunity   = errors.New("you have been banned from this community")
+	ErrBanNotFound           = errors.New("user is not banned from this community")
+)
+
+type CommunityService struct {
+	repo       usecase.CommunityRepository
+	threads    *ThreadService
+	threadRepo usecase.ThreadRepository
+}
+
+func NewCommunityService(repo usecase.CommunityRepository, threads *ThreadService, threadRepo usecase.ThreadRepository) *CommunityService {
+	return &CommunityService{repo: repo, threads: threads, threadRepo: threadRepo}
+}
+
+// Create stores a new community owned by community.OwnerID.
+func (s *CommunityService) Create(community *model.Community) (*model.Community, error) {
+	if err := ValidateCommunity(community); err != nil {
+		return nil, err
+	}
+	if existing, _ := s.repo.GetByName(community.Name); existing != nil {
+		return nil, ErrCommunityNameTaken
+	}
+	community.ID = uuid.New()
+	community.CreatedAt = time.Now()
+	if err := s.repo.Create(community); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(community.ID, community.OwnerID)
+}
+
+func (s *CommunityService) Get(id, viewerID uuid.UUID) (*model.Community, error) {
+	community, err := s.repo.GetByID(id, viewerID)
+	if err != nil {
+		return nil, ErrCommunityNotFound
+	}
+	return community, nil
+}
+
+func (s *CommunityService) List(query string, viewerID uuid.UUID, limit, offset int) ([]*model.Community, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.List(normalizeLine(query), viewerID, normalizePageSize(limit), offset)
+}
+
+// Update changes the name and/or description; only the owner may do this.
+func (s *CommunityService) Update(id, actorID uuid.UUID, name, description *string) (*model.Community, error) {
+	community, err := s.Get(id, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if community.MyRole != model.CommunityRoleOwner {
+		return nil, ErrNotCommunityOwner
+	}
+	if name != nil {
+		community.Name = *name
+	}
+	if description != nil {
+		community.Description = *description
+	}
+	if err := ValidateCommunity(community); err != nil {
+		return nil, err
+	}
+	if existing, _ := s.repo.GetByName(community.Name); existing != nil && existing.ID != id {
+		return nil, ErrCommunityNameTaken
+	}
+	if err := s.repo.Update(community); err != nil {
+		return nil, err
+	}
+	return community, nil
+}
+
+// Delete removes the community and soft-deletes every thread posted into it;
+// only the owner may do this.
+func (s *CommunityService) Delete(id, actorID uuid.UUID) error {
+	community, err := s.Get(id, actorID)
+	if err != nil {
+		return err
+	}
+	if community.MyRole != model.CommunityRoleOwner {
+		return ErrNotCommunityOwner
+	}
+	return s.repo.Delete(id, actorID)
+}
+
+// Join adds the caller as a member unless a moderator has banned them.
+func (s *CommunityService) Join(id, userID uuid.UUID) error {
+	if _, err := s.Get(id, userID); err != nil {
+		return err
+	}
+	banned, err := s.repo.IsBanned(id, userID)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return ErrBannedFromCommunity
+	}
+	return s.repo.AddMember(id, userID, model.CommunityRoleMember)
+}
+
+// Leave removes the caller from the community. Their threads stay in it.
+func (s *CommunityService) Leave(id, userID uuid.UUID) error {
+	role, err := s.repo.GetRole(id, userID)
+	if err != nil {
+		return err
+	}
+	if role == model.CommunityRoleOwner {
+		return ErrCommunityOwner
+	}
+	return s.repo.RemoveMember(id, userID)
+}
+
+func (s *CommunityService) GetMembers(id uuid.UUID, limit, offset int) ([]*model.CommunityMember, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.GetMembers(id, normalizePageSize(limit), offset)
+}
+
+// RemoveMember lets moderators remove a member, who is banned from joining
+// again until Unban. Removing another moderator is reserved to the owner, and
+// the owner cannot be removed.
+func (s *CommunityService) RemoveMember(id, actorID, userID uuid.UUID) error {
+	community, err := s.moderated(id, actorID)
+	if err != nil {
+		return err
+	}
+	role, err := s.repo.GetRole(id, userID)
+	if err != nil {
+		return err
+	}
+	switch role {
+	case "":
+		return ErrMemberNotFound
+	case model.CommunityRoleOwner:
+		return ErrCommunityOwner
+	case model.CommunityRoleModerator:
+		if community.MyRole != model.CommunityRoleOwner {
+			return ErrNotCommunityOwner
+		}
+	}
+	return s.repo.BanMember(id, userID, actorID)
+}
+
+// Unban lets moderators allow a removed member to join again.
+func (s *CommunityService) Unban(id, actorID, userID uuid.UUID) error {
+	if _, err := s.moderated(id, actorID); err != nil {
+		return err
+	}
+	found, err := s.repo.Unban(id, userID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrBanNotFound
+	}
+	return nil
+}
+
+// SetModerator promotes a member to moderator or demotes them back; only the
+// owner may do this.
+func (s *CommunityService) SetModerator(id, actorID, userID uuid.UUID, moderator bool) error {
+	community, err := s.Get(id, actorID)
+	if err != nil {
+		return err
+	}
+	if community.MyRole != model.CommunityRoleOwner {
+		return ErrNotCommunityOwner
+	}
+	role, err := s.repo.GetRole(id, userID)
+	if err != nil {
+		return err
+	}
+	switch role {
+	case "":
+		return ErrMemberNotFound
+	case model.CommunityRoleOwner:
+		return ErrCommunityOwner
+	}
+	if moderator {
+		return s.repo.SetRole(id, userID, model.CommunityRoleModerator)
+	}
+	return s.repo.SetRole(id, userID, model.CommunityRoleMember)
+}
+
+// GetThreads is the community's feed.
+func (s *CommunityService) GetThreads(id, viewerID uuid.UUID, limit, offset int) ([]*model.Thread, error) {
+	if _, err := s.Get(id, viewerID); err != nil {
+		return nil, err
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	return s.threadRepo.GetByCommunity(id, viewerID, normalizePageSize(limit), offset)
+}
+
+// RemoveThread lets a community moderator delete a thread posted into the
+// community.
+func (s *CommunityService) RemoveThread(id, actorID, threadID uuid.UUID) error {
+	if _, err := s.moderated(id, actorID); err != nil {
+		return err
+	}
+	thread, err := s.threadRepo.GetThreadById(threadID, actorID)
+	if err != nil || thread.CommunityID == nil || *thread.CommunityID != id {
+		return ErrThreadNotFound
+	}
+	return s.threads.Delete(threadID, actorID)
+}
+
+// moderated returns the community when actorID is one of its moderators.
+func (s *CommunityService) moderated(id, actorID uuid.UUID) (*model.Community, error) {
+	community, err := s.Get(id, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !community.CanModerate() {
+		return nil, ErrNotCommunityModerator
+	}
+	return community, nil
+}
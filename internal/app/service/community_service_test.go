@@ -0,0 +1,85 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/google/uuid"
+	"testing"
+)
+
+type membership struct{ community, user uuid.UUID }
+
+// fakeCommunityRepo keeps roles and bans for a single community in memory.
+// Other methods are not expected to be called.
+type fakeCommunityRepo struct {
+	usecase.CommunityRepository
+	id     uuid.UUID
+	roles  map[uuid.UUID]string
+	banned map[membership]bool
+}
+
+func (r *fakeCommunityRepo) GetByID(id, viewerID uuid.UUID) (*model.Community, error) {
+	if id != r.id {
+		return nil, errors.New("no rows")
+	}
+	return &model.Community{ID: id, MyRole: r.roles[viewerID]}, nil
+}
+
+func (r *fakeCommunityRepo) GetRole(id, userID uuid.UUID) (string, error) {
+	return r.roles[userID], nil
+}
+
+func (r *fakeCommunityRepo) AddMember(id, userID uuid.UUID, role string) error {
+	r.roles[userID] = role
+	return nil
+}
+
+func (r *fakeCommunityRepo) BanMember(id, userID, bannedBy uuid.UUID) error {
+	delete(r.roles, userID)
+	r.banned[membership{id, userID}] = true
+	return nil
+}
+
+func (r *fakeCommunityRepo) Unban(id, userID uuid.UUID) (bool, error) {
+	found := r.banned[membership{id, userID}]
+	delete(r.banned, membership{id, userID})
+	return found, nil
+}
+
+func (r *fakeCommunityRepo) IsBanned(id, userID uuid.UUID) (bool, error) {
+	return r.banned[membership{id, userID}], nil
+}
+
+func TestCommunityRemovedMemberCannotRejoin(t *testing.T) {
+	id, owner, mod, member := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	repo := &fakeCommunityRepo{
+		id:     id,
+		roles:  map[uuid.UUID]string{owner: model.CommunityRoleOwner, mod: model.CommunityRoleModerator, member: model.CommunityRoleMember},
+		banned: map[membership]bool{},
+	}
+	s := NewCommunityService(repo, nil, nil)
+
+	steps := []struct {
+		name    string
+		run     func() error
+		wantErr error
+	}{
+		{"moderator removes member", func() error { return s.RemoveMember(id, mod, member) }, nil},
+		{"removed member cannot rejoin", func() error { return s.Join(id, member) }, ErrBannedFromCommunity},
+		{"member cannot lift their own ban", func() error { return s.Unban(id, member, member) }, ErrNotCommunityModerator},
+		{"moderator lifts the ban", func() error { return s.Unban(id, mod, member) }, nil},
+		{"lifting it again finds nothing", func() error { return s.Unban(id, mod, member) }, ErrBanNotFound},
+		{"member rejoins", func() error { return s.Join(id, member) }, nil},
+		{"moderator cannot remove the owner", func() error { return s.RemoveMember(id, mod, owner) }, ErrCommunityOwner},
+		{"unknown community", func() error { return s.Join(uuid.New(), member) }, ErrCommunityNotFound},
+	}
+	for _, step := range steps {
+		if err := step.run(); !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: got %v, want %v", step.name, err, step.wantErr)
+		}
+	}
+	if repo.roles[member] != model.CommunityRoleMember {
+		t.Errorf("member role = %q after rejoining", repo.roles[member])
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"encoding/base64"
+	"github.com/google/uuid"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// encodeCursor builds an opaque page cursor from the created_at and id of
+// the last item on a page.
+func encodeCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeCursor reverses encodeCursor. ok is false for an empty cursor, which
+// means the first page.
+func decodeCursor(cursor string) (createdAt time.Time, id uuid.UUID, ok bool, err error) {
+	if cursor == "" {
+		return time.Time{}, uuid.Nil, false, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, false, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return time.Time{}, uuid.Nil, false, ErrInvalidCursor
+	}
+	if createdAt, err = time.Parse(time.RFC3339Nano, parts[0]); err != nil {
+		return time.Time{}, uuid.Nil, false, ErrInvalidCursor
+	}
+	if id, err = uuid.Parse(parts[1]); err != nil {
+		return time.Time{}, uuid.Nil, false, ErrInvalidCursor
+	}
+	return createdAt, id, true, nil
+}
+
+// encodeSeqCursor builds an opaque page cursor from the sequence number of
+// the last item on a page.
+func encodeSeqCursor(seq int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(seq, 10)))
+}
+
+// decodeSeqCursor reverses encodeSeqCursor. ok is false for an empty cursor,
+// which means the first page.
+func decodeSeqCursor(cursor string) (seq int64, ok bool, err error) {
+	if cursor == "" {
+		return 0, false, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false, ErrInvalidCursor
+	}
+	if seq, err = strconv.ParseInt(string(raw), 10, 64); err != nil || seq <= 0 {
+		return 0, false, ErrInvalidCursor
+	}
+	return seq, true, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	id := uuid.New()
+	tests := []struct {
+		name      string
+		createdAt time.Time
+	}{
+		{"utc", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{"nanoseconds", time.Date(2024, 3, 1, 12, 0, 0, 123456789, time.UTC)},
+		{"other zone", time.Date(2024, 3, 1, 12, 0, 0, 5, time.FixedZone("UTC+3", 3*60*60))},
+		{"zero", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			createdAt, gotID, ok, err := decodeCursor(encodeCursor(tt.createdAt, id))
+			if err != nil || !ok {
+				t.Fatalf("got ok=%v err=%v", ok, err)
+			}
+			if !createdAt.Equal(tt.createdAt) || gotID != id {
+				t.Errorf("got %v %v, want %v %v", createdAt, gotID, tt.createdAt, id)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejects(t *testing.T) {
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "***"},
+		{"truncated", encodeCursor(time.Now(), uuid.New())[:30]},
+		{"no separator", encode("2024-03-01T12:00:00Z")},
+		{"bad time", encode("yesterday|" + uuid.NewString())},
+		{"bad id", encode("2024-03-01T12:00:00Z|not-a-uuid")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, ok, err := decodeCursor(tt.cursor); !errors.Is(err, ErrInvalidCursor) || ok {
+				t.Errorf("got ok=%v err=%v, want ErrInvalidCursor", ok, err)
+			}
+		})
+	}
+	if _, _, ok, err := decodeCursor(""); ok || err != nil {
+		t.Errorf("empty cursor: got ok=%v err=%v, want the first page", ok, err)
+	}
+}
+
+func TestSeqCursor(t *testing.T) {
+	for _, seq := range []int64{1, 42, 1 << 62} {
+		got, ok, err := decodeSeqCursor(encodeSeqCursor(seq))
+		if err != nil || !ok || got != seq {
+			t.Errorf("round trip of %d: got %d, %v, %v", seq, got, ok, err)
+		}
+	}
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+	for _, cursor := range []string{"***", encode("abc"), encode("0"), encode("-5"), encode("99999999999999999999")} {
+		if _, ok, err := decodeSeqCursor(cursor); !errors.Is(err, ErrInvalidCursor) || ok {
+			t.Errorf("decodeSeqCursor(%q) = %v, %v, want ErrInvalidCursor", cursor, ok, err)
+		}
+	}
+	if _, ok, err := decodeSeqCursor(""); ok || err != nil {
+		t.Errorf("empty cursor: got ok=%v err=%v, want the first page", ok, err)
+	}
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const purgeBatchSize = 100
+
+// DeletedPurger periodically hard-deletes threads and comments whose restore
+// window has closed. Like the reaper it is safe to run on every replica.
+type DeletedPurger struct {
+	threads  *ThreadService
+	comments *CommentService
+	interval time.Duration
+}
+
+func NewDeletedPurger(threads *ThreadService, comments *CommentService, interval time.Duration) *DeletedPurger {
+	return &DeletedPurger{threads: threads, comments: comments, interval: interval}
+}
+
+func (p *DeletedPurger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.purge("threads", p.threads.PurgeDeleted)
+		p.purge("comments", p.comments.PurgeDeleted)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *DeletedPurger) purge(what string, purgeBatch func(now time.Time, batchSize int) (int, error)) {
+	for {
+		n, err := purgeBatch(time.Now(), purgeBatchSize)
+		if err != nil {
+			log.Println("Failed to purge deleted "+what+":", err)
+			return
+		}
+		if n < purgeBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrFollowSelf   = errors.New("you cannot follow yourself")
+	ErrUserNotFound = errors.New("user not found")
+)
+
+type FollowService struct {
+	repo     usecase.FollowRepository
+	userRepo usecase.UserRepository
+}
+
+func NewFollowService(repo usecase.FollowRepository, userRepo usecase.UserRepository) *FollowService {
+	return &FollowService{repo: repo, userRepo: userRepo}
+}
+func (s *FollowService) Follow(followerID, followeeID uuid.UUID) error {
+	if followerID == followeeID {
+		return ErrFollowSelf
+	}
+	if _, err := s.userRepo.GetByID(followeeID); err != nil {
+		return ErrUserNotFound
+	}
+	return s.repo.Follow(followerID, followeeID)
+}
+func (s *FollowService) Unfollow(followerID, followeeID uuid.UUID) error {
+	return s.repo.Unfollow(followerID, followeeID)
+}
+func (s *FollowService) GetFollowers(userID uuid.UUID) ([]*model.PublicUser, error) {
+	return s.repo.GetFollowers(userID)
+}
+func (s *FollowService) GetFollowing(userID uuid.UUID) ([]*model.PublicUser, error) {
+	return s.repo.GetFollowing(userID)
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+)
+
+const maxHashtagLength = 50
+
+var hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&/#])#([\p{L}\p{N}_]+)`)
+
+// ExtractHashtags returns the distinct, normalized hashtags found in content
+// in order of first appearance.
+func ExtractHashtags(content string) []string {
+	seen := make(map[string]bool)
+	tags := make([]string, 0)
+	for _, m := range hashtagPattern.FindAllStringSubmatch(content, -1) {
+		tag := NormalizeTag(m[1])
+		if tag == "" || len([]rune(tag)) > maxHashtagLength || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// NormalizeTag lowercases a tag and strips a leading '#'.
+func NormalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+}
@@ -0,0 +1,402 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	_ "image/png"
+)
+
+const (
+	VariantAvatar64  = "avatar_64"
+	VariantAvatar256 = "avatar_256"
+	VariantFeed      = "feed"
+	VariantFull      = "full"
+
+	feedImageSize = 720
+	fullImageSize = 2048
+	jpegQuality   = 85
+
+	// maxImagePixels rejects decompression bombs before they are decoded.
+	// For an animated GIF it caps the pixels of all frames together.
+	maxImagePixels = 50_000_000
+)
+
+var ErrImageTooLarge = errors.New("image dimensions are too large")
+
+// imageVariant describes one rendition. Square variants are center-cropped,
+// the others are scaled to fit within size x size.
+type imageVariant struct {
+	name   string
+	size   int
+	square bool
+}
+
+var imageVariants = map[string][]imageVariant{
+	model.MediaKindAvatar: {
+		{name: VariantAvatar64, size: 64, square: true},
+		{name: VariantAvatar256, size: 256, square: true},
+	},
+	model.MediaKindThread: {
+		{name: VariantFeed, size: feedImageSize},
+		{name: VariantFull, size: fullImageSize},
+	},
+}
+
+// ProcessedImage holds the encoded variants of an upload. Every variant is
+// re-encoded from pixels, so none of the original metadata (EXIF, GPS, XMP,
+// comments) survives.
+type ProcessedImage struct {
+	ContentType map[string]string
+	Data        map[string][]byte
+	Size        map[string]image.Point
+}
+
+type imageJob struct {
+	ctx    context.Context
+	kind   string
+	data   []byte
+	result chan imageResult
+}
+
+type imageResult struct {
+	image *ProcessedImage
+	err   error
+}
+
+// ImagePipeline decodes and resizes images on a fixed number of workers so a
+// burst of uploads cannot use more than that many CPUs.
+type ImagePipeline struct {
+	jobs chan imageJob
+}
+
+func NewImagePipeline(workers int) *ImagePipeline {
+	if workers < 1 {
+		workers = 1
+	}
+	p := &ImagePipeline{jobs: make(chan imageJob)}
+	for i := 0; i < workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Process waits for a free worker, then returns the variants for an upload of
+// the given kind (model.MediaKindAvatar or model.MediaKindThread).
+func (p *ImagePipeline) Process(ctx context.Context, kind string, data []byte) (*ProcessedImage, error) {
+	job := imageJob{ctx: ctx, kind: kind, data: data, result: make(chan imageResult, 1)}
+	select {
+	case p.jobs <- job:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	select {
+	case res := <-job.result:
+		return res.image, res.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (p *ImagePipeline) work() {
+	for job := range p.jobs {
+		if job.ctx.Err() != nil {
+			job.result <- imageResult{err: job.ctx.Err()}
+			continue
+		}
+		img, err := processImage(job.kind, job.data)
+		job.result <- imageResult{image: img, err: err}
+	}
+}
+
+func processImage(kind string, data []byte) (*ProcessedImage, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedMedia
+	}
+	if cfg.Width*cfg.Height > maxImagePixels {
+		return nil, ErrImageTooLarge
+	}
+	out := &ProcessedImage{ContentType: map[string]string{}, Data: map[string][]byte{}, Size: map[string]image.Point{}}
+
+	var src image.Image
+	var anim *gif.GIF
+	if format == "gif" {
+		frames, ok := gifFrameCount(data)
+		if !ok {
+			return nil, ErrUnsupportedMedia
+		}
+		if frames*cfg.Width*cfg.Height > maxImagePixels {
+			return nil, ErrImageTooLarge
+		}
+		if anim, err = gif.DecodeAll(bytes.NewReader(data)); err != nil {
+			return nil, ErrUnsupportedMedia
+		}
+		src = anim.Image[0]
+	} else {
+		if src, _, err = image.Decode(bytes.NewReader(data)); err != nil {
+			return nil, ErrUnsupportedMedia
+		}
+		if format == "jpeg" {
+			src = applyOrientation(src, jpegOrientation(data))
+		}
+	}
+
+	for _, v := range imageVariants[kind] {
+		// Animated GIFs keep their animation at full size; re-encoding the
+		// frames still drops comment and application extensions.
+		if anim != nil && len(anim.Image) > 1 && v.name == VariantFull {
+			var buf bytes.Buffer
+			if err := gif.EncodeAll(&buf, &gif.GIF{Image: anim.Image, Delay: anim.Delay, LoopCount: anim.LoopCount,
+				Disposal: anim.Disposal, Config: anim.Config, BackgroundIndex: anim.BackgroundIndex}); err != nil {
+				return nil, err
+			}
+			out.Data[v.name] = buf.Bytes()
+			out.ContentType[v.name] = "image/gif"
+			out.Size[v.name] = image.Pt(anim.Config.Width, anim.Config.Height)
+			continue
+		}
+		var resized image.Image
+		if v.square {
+			resized = resize(cropSquare(src), v.size, v.size)
+		} else {
+			w, h := fitWithin(src.Bounds().Dx(), src.Bounds().Dy(), v.size)
+			resized = resize(src, w, h)
+		}
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, flatten(resized), &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		out.Data[v.name] = buf.Bytes()
+		out.ContentType[v.name] = "image/jpeg"
+		out.Size[v.name] = resized.Bounds().Size()
+	}
+	return out, nil
+}
+
+// gifFrameCount counts the frames of a GIF by walking its block structure
+// without decompressing anything, so DecodeAll is only called on files whose
+// total size is known to be acceptable.
+func gifFrameCount(data []byte) (int, bool) {
+	if len(data) < 13 || (string(data[:6]) != "GIF87a" && string(data[:6]) != "GIF89a") {
+		return 0, false
+	}
+	i := 13
+	if data[10]&0x80 != 0 {
+		i += 3 << (data[10]&0x07 + 1)
+	}
+	// skipSubBlocks returns the offset after a chain of data sub-blocks.
+	skipSubBlocks := func(i int) int {
+		for i < len(data) {
+			n := int(data[i])
+			i++
+			if n == 0 {
+				return i
+			}
+			i += n
+		}
+		return -1
+	}
+	frames := 0
+	for i < len(data) {
+		switch data[i] {
+		case 0x21: // extension: label, then sub-blocks
+			if i = skipSubBlocks(i + 2); i < 0 {
+				return 0, false
+			}
+		case 0x2C: // image descriptor, optional local color table, LZW code size, sub-blocks
+			if i+10 > len(data) {
+				return 0, false
+			}
+			flags := data[i+9]
+			i += 10
+			if flags&0x80 != 0 {
+				i += 3 << (flags&0x07 + 1)
+			}
+			if i = skipSubBlocks(i + 1); i < 0 {
+				return 0, false
+			}
+			frames++
+		case 0x3B: // trailer
+			return frames, frames > 0
+		default:
+			return 0, false
+		}
+	}
+	// A missing trailer is tolerated, as it is by most decoders.
+	return frames, frames > 0
+}
+
+// fitWithin scales w x h down, never up, so the longer side is at most max.
+func fitWithin(w, h, max int) (int, int) {
+	if w <= max && h <= max {
+		return w, h
+	}
+	if w >= h {
+		return max, maxInt(1, h*max/w)
+	}
+	return maxInt(1, w*max/h), max
+}
+
+func cropSquare(src image.Image) image.Image {
+	b := src.Bounds()
+	side := b.Dx()
+	if b.Dy() < side {
+		side = b.Dy()
+	}
+	x := b.Min.X + (b.Dx()-side)/2
+	y := b.Min.Y + (b.Dy()-side)/2
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(dst, dst.Bounds(), src, image.Pt(x, y), draw.Src)
+	return dst
+}
+
+// resize scales src to w x h by averaging every source pixel that falls into
+// a destination pixel, which gives clean results when shrinking photos.
+func resize(src image.Image, w, h int) *image.RGBA {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if sw == w && sh == h {
+		draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Src)
+		return dst
+	}
+	for y := 0; y < h; y++ {
+		y0 := b.Min.Y + y*sh/h
+		y1 := maxInt(y0+1, b.Min.Y+(y+1)*sh/h)
+		for x := 0; x < w; x++ {
+			x0 := b.Min.X + x*sw/w
+			x1 := maxInt(x0+1, b.Min.X+(x+1)*sw/w)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.SetRGBA(x, y, color.RGBA{R: uint8(r / n >> 8), G: uint8(g / n >> 8), B: uint8(bl / n >> 8), A: uint8(a / n >> 8)})
+		}
+	}
+	return dst
+}
+
+// flatten composites transparent images onto white, since JPEG has no alpha.
+func flatten(src image.Image) image.Image {
+	dst := image.NewRGBA(src.Bounds())
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, src.Bounds().Min, draw.Over)
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag (1-8) from a JPEG, or 1 when
+// there is none. Cameras store portrait photos sideways and rely on this tag,
+// so it has to be applied before the EXIF block is thrown away.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		seg := data[i+4 : i+2+size]
+		if marker == 0xE1 && len(seg) > 6 && string(seg[:6]) == "Exif\x00\x00" {
+			return exifOrientation(seg[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for e := 0; e < entries; e++ {
+		off := ifd + 2 + e*12
+		if off+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[off:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[off+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// applyOrientation rotates and flips src so it displays upright.
+func applyOrientation(src image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, src.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,290 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"testing"
+)
+
+var (
+	red   = color.RGBA{R: 255, A: 255}
+	green = color.RGBA{G: 255, A: 255}
+	blue  = color.RGBA{B: 255, A: 255}
+	white = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+// quadrants returns a w x h image whose top-left, top-right, bottom-left and
+// bottom-right quarters are red, green, blue and white.
+func quadrants(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			switch {
+			case x < w/2 && y < h/2:
+				img.Set(x, y, red)
+			case y < h/2:
+				img.Set(x, y, green)
+			case x < w/2:
+				img.Set(x, y, blue)
+			default:
+				img.Set(x, y, white)
+			}
+		}
+	}
+	return img
+}
+
+func TestFitWithin(t *testing.T) {
+	tests := []struct {
+		w, h, max    int
+		wantW, wantH int
+	}{
+		{100, 50, 720, 100, 50},
+		{720, 720, 720, 720, 720},
+		{1440, 720, 720, 720, 360},
+		{720, 1440, 720, 360, 720},
+		{10000, 1, 720, 720, 1},
+	}
+	for _, tt := range tests {
+		if w, h := fitWithin(tt.w, tt.h, tt.max); w != tt.wantW || h != tt.wantH {
+			t.Errorf("fitWithin(%d, %d, %d) = %d, %d, want %d, %d", tt.w, tt.h, tt.max, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestResize(t *testing.T) {
+	tests := []struct {
+		name   string
+		src    image.Image
+		w, h   int
+		pixels map[image.Point]color.RGBA
+	}{
+		{
+			name: "same size copies",
+			src:  quadrants(4, 4), w: 4, h: 4,
+			pixels: map[image.Point]color.RGBA{{0, 0}: red, {3, 0}: green, {0, 3}: blue, {3, 3}: white},
+		},
+		{
+			name: "halving keeps quadrants",
+			src:  quadrants(8, 8), w: 4, h: 4,
+			pixels: map[image.Point]color.RGBA{{0, 0}: red, {3, 0}: green, {0, 3}: blue, {3, 3}: white},
+		},
+		{
+			name: "shrinking averages",
+			src:  quadrants(2, 2), w: 1, h: 1,
+			// (255+0+0+255)/4, (0+255+0+255)/4, (0+0+255+255)/4
+			pixels: map[image.Point]color.RGBA{{0, 0}: {R: 127, G: 127, B: 127, A: 255}},
+		},
+		{
+			name: "offset bounds",
+			src:  quadrants(8, 8).SubImage(image.Rect(4, 4, 8, 8)), w: 2, h: 2,
+			pixels: map[image.Point]color.RGBA{{0, 0}: white, {1, 1}: white},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resize(tt.src, tt.w, tt.h)
+			if size := got.Bounds().Size(); size != image.Pt(tt.w, tt.h) {
+				t.Fatalf("size = %v, want %dx%d", size, tt.w, tt.h)
+			}
+			for p, want := range tt.pixels {
+				if c := got.RGBAAt(p.X, p.Y); c != want {
+					t.Errorf("pixel %v = %v, want %v", p, c, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCropSquare(t *testing.T) {
+	tests := []struct {
+		name     string
+		src      image.Image
+		side     int
+		topLeft  color.RGBA
+		topRight color.RGBA
+	}{
+		// A 6x2 image cropped to its middle 2x2 spans the red/green border.
+		{"landscape", quadrants(6, 2), 2, red, green},
+		// A 2x6 image cropped to its middle 2x2 spans the red/blue border, so
+		// its top row is still red and green.
+		{"portrait", quadrants(2, 6), 2, red, green},
+		{"square", quadrants(4, 4), 4, red, green},
+		{"offset bounds", quadrants(8, 8).SubImage(image.Rect(4, 0, 8, 2)), 2, green, green},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := cropSquare(tt.src).(*image.RGBA)
+			if size := got.Bounds().Size(); size != image.Pt(tt.side, tt.side) {
+				t.Fatalf("size = %v, want %dx%d", size, tt.side, tt.side)
+			}
+			if c := got.RGBAAt(0, 0); c != tt.topLeft {
+				t.Errorf("top left = %v, want %v", c, tt.topLeft)
+			}
+			if c := got.RGBAAt(tt.side-1, 0); c != tt.topRight {
+				t.Errorf("top right = %v, want %v", c, tt.topRight)
+			}
+		})
+	}
+}
+
+// exifJPEG encodes img as a JPEG with an APP1 segment carrying the given
+// orientation tag, using the given TIFF byte order.
+func exifJPEG(t *testing.T, img image.Image, orientation int, order binary.ByteOrder) []byte {
+	t.Helper()
+	var enc bytes.Buffer
+	if err := jpeg.Encode(&enc, img, &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatal(err)
+	}
+	tiff := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8) // offset of IFD0
+	order.PutUint16(tiff[8:], 1) // one entry
+	order.PutUint16(tiff[10:], 0x0112)
+	order.PutUint16(tiff[12:], 3) // SHORT
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], uint16(orientation))
+	seg := append([]byte("Exif\x00\x00"), tiff...)
+
+	out := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	out = binary.BigEndian.AppendUint16(out, uint16(len(seg)+2))
+	out = append(out, seg...)
+	return append(out, enc.Bytes()[2:]...)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	img := quadrants(8, 8)
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"little endian", exifJPEG(t, img, 6, binary.LittleEndian), 6},
+		{"big endian", exifJPEG(t, img, 8, binary.BigEndian), 8},
+		{"out of range", exifJPEG(t, img, 9, binary.LittleEndian), 1},
+		{"empty", nil, 1},
+		{"not a jpeg", []byte("GIF89a"), 1},
+		{"truncated", exifJPEG(t, img, 6, binary.LittleEndian)[:20], 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := jpegOrientation(tt.data); got != tt.want {
+				t.Errorf("got %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	// A 4x2 image: top row red then green, bottom row blue then white.
+	src := quadrants(4, 2)
+	tests := []struct {
+		orientation int
+		size        image.Point
+		// corners lists top-left, top-right, bottom-left, bottom-right.
+		corners [4]color.RGBA
+	}{
+		{1, image.Pt(4, 2), [4]color.RGBA{red, green, blue, white}},
+		{2, image.Pt(4, 2), [4]color.RGBA{green, red, white, blue}},
+		{3, image.Pt(4, 2), [4]color.RGBA{white, blue, green, red}},
+		{4, image.Pt(4, 2), [4]color.RGBA{blue, white, red, green}},
+		{5, image.Pt(2, 4), [4]color.RGBA{red, blue, green, white}},
+		{6, image.Pt(2, 4), [4]color.RGBA{blue, red, white, green}},
+		{7, image.Pt(2, 4), [4]color.RGBA{white, green, blue, red}},
+		{8, image.Pt(2, 4), [4]color.RGBA{green, white, red, blue}},
+	}
+	for _, tt := range tests {
+		got := applyOrientation(src, tt.orientation)
+		b := got.Bounds()
+		if b.Size() != tt.size {
+			t.Errorf("orientation %d: size = %v, want %v", tt.orientation, b.Size(), tt.size)
+			continue
+		}
+		corners := [4]image.Point{{b.Min.X, b.Min.Y}, {b.Max.X - 1, b.Min.Y}, {b.Min.X, b.Max.Y - 1}, {b.Max.X - 1, b.Max.Y - 1}}
+		for i, p := range corners {
+			if c := color.RGBAModel.Convert(got.At(p.X, p.Y)); c != tt.corners[i] {
+				t.Errorf("orientation %d: corner %v = %v, want %v", tt.orientation, p, c, tt.corners[i])
+			}
+		}
+	}
+}
+
+func TestProcessImageAppliesOrientation(t *testing.T) {
+	data := exifJPEG(t, quadrants(400, 200), 6, binary.BigEndian)
+	out, err := processImage(model.MediaKindThread, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := out.Size[VariantFull]; size != image.Pt(200, 400) {
+		t.Errorf("full size = %v, want 200x400", size)
+	}
+	if bytes.Contains(out.Data[VariantFull], []byte("Exif")) {
+		t.Error("EXIF block survived re-encoding")
+	}
+}
+
+func encodeGIF(t *testing.T, frames, w, h int) []byte {
+	t.Helper()
+	anim := &gif.GIF{}
+	palette := color.Palette{color.Black, color.White}
+	for i := 0; i < frames; i++ {
+		anim.Image = append(anim.Image, image.NewPaletted(image.Rect(0, 0, w, h), palette))
+		anim.Delay = append(anim.Delay, 10)
+	}
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGIFFrameCount(t *testing.T) {
+	three := encodeGIF(t, 3, 4, 4)
+	tests := []struct {
+		name   string
+		data   []byte
+		frames int
+		ok     bool
+	}{
+		{"one frame", encodeGIF(t, 1, 4, 4), 1, true},
+		{"three frames", three, 3, true},
+		{"missing trailer", three[:len(three)-1], 3, true},
+		{"truncated frame", three[:len(three)-8], 0, false},
+		{"not a gif", []byte("\x89PNG\r\n\x1a\n00000"), 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			frames, ok := gifFrameCount(tt.data)
+			if frames != tt.frames || ok != tt.ok {
+				t.Errorf("got %d, %v, want %d, %v", frames, ok, tt.frames, tt.ok)
+			}
+		})
+	}
+}
+
+func TestProcessImageCapsGIFFrames(t *testing.T) {
+	// 200 frames of 600x600 are 72 million pixels, each frame well under
+	// the limit on its own.
+	_, err := processImage(model.MediaKindThread, encodeGIF(t, 200, 600, 600))
+	if !errors.Is(err, ErrImageTooLarge) {
+		t.Errorf("got %v, want ErrImageTooLarge", err)
+	}
+	out, err := processImage(model.MediaKindThread, encodeGIF(t, 3, 64, 64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.ContentType[VariantFull] != "image/gif" {
+		t.Errorf("full variant is %s, want image/gif", out.ContentType[VariantFull])
+	}
+}
@@ -3,36 +3,165 @@ package service
 import (
 	"WebMessanger/internal/model"
 	"WebMessanger/internal/usecase"
+	"errors"
 	"github.com/google/uuid"
+	"os"
+	"strings"
 	"time"
 )
 
+var DefaultReactionSet = []string{model.DefaultReaction, "❤️", "😂", "😮", "😢", "😡"}
+
+var ErrInvalidReaction = errors.New("reaction is not allowed")
+
 type LikeService struct {
-	repo usecase.LikeRepository
+	repo      usecase.LikeRepository
+	comments  usecase.CommentRepository
+	access    *ThreadAccess
+	reactions []string
+}
+
+func NewLikeService(repo usecase.LikeRepository, comments usecase.CommentRepository, access *ThreadAccess, reactions []string) *LikeService {
+	return &LikeService{repo: repo, comments: comments, access: access, reactions: reactions}
 }
 
-func NewLikeService(repo usecase.LikeRepository) *LikeService {
-	return &LikeService{repo: repo}
+// ReactionSetFromEnv reads the comma-separated REACTION_SET variable. The
+// default reaction is always part of the set so the like endpoints keep working.
+func ReactionSetFromEnv() []string {
+	raw := os.Getenv("REACTION_SET")
+	if raw == "" {
+		return DefaultReactionSet
+	}
+	reactions := []string{model.DefaultReaction}
+	for _, r := range strings.Split(raw, ",") {
+		r = strings.TrimSpace(r)
+		if r != "" && r != model.DefaultReaction {
+			reactions = append(reactions, r)
+		}
+	}
+	return reactions
 }
+
+// AddLike leaves the user's reaction on a target they have not reacted to
+// yet. An earlier reaction is kept and returned instead, so the legacy like
+// endpoints never overwrite a reaction picked through the reaction API.
 func (s *LikeService) AddLike(like *model.Like) (*model.Like, error) {
+	return s.add(like, s.repo.AddLike)
+}
+
+// SetReaction leaves the user's reaction on a target, replacing any earlier
+// one.
+func (s *LikeService) SetReaction(like *model.Like) (*model.Like, error) {
+	return s.add(like, s.repo.SetReaction)
+}
+
+func (s *LikeService) add(like *model.Like, save func(*model.Like) error) (*model.Like, error) {
+	switch like.TargetType {
+	case model.LikeTargetThread:
+		like.ThreadID = like.TargetID
+	case model.LikeTargetComment:
+		like.ThreadID = uuid.Nil
+	default:
+		return nil, errors.New("invalid like target")
+	}
+	if like.Reaction == "" {
+		like.Reaction = model.DefaultReaction
+	}
+	if !s.isAllowed(like.Reaction) {
+		return nil, ErrInvalidReaction
+	}
+	thread, err := s.checkTarget(like.TargetType, like.TargetID, like.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// Reacting is a lightweight reply, so it follows the same rules.
+	if err := s.access.CheckReply(thread, like.UserID); err != nil {
+		return nil, err
+	}
 	like.ID = uuid.New()
 	like.CreatedAt = time.Now()
 
-	err := s.repo.AddLike(like)
-	if err != nil {
+	if err := save(like); err != nil {
 		return nil, err
 	}
 	return like, nil
 }
 
-func (s *LikeService) RemoveLike(threadID uuid.UUID, userID uuid.UUID) error {
-	return s.repo.RemoveLike(threadID, userID)
+// RemoveLike takes back the user's like. Any other reaction is left alone.
+func (s *LikeService) RemoveLike(targetType string, targetID uuid.UUID, userID uuid.UUID) error {
+	return s.repo.RemoveLike(targetType, targetID, userID, model.DefaultReaction)
+}
+
+// RemoveReaction takes back whatever reaction the user left on the target.
+func (s *LikeService) RemoveReaction(targetType string, targetID uuid.UUID, userID uuid.UUID) error {
+	return s.repo.RemoveLike(targetType, targetID, userID, "")
+}
+
+func (s *LikeService) GetLikesByThread(threadID, viewerID uuid.UUID) ([]*model.Like, error) {
+	if _, err := s.checkTarget(model.LikeTargetThread, threadID, viewerID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetLikesByTarget(model.LikeTargetThread, threadID)
 }
 
-func (s *LikeService) GetLikesByThread(threadID uuid.UUID) ([]*model.Like, error) {
-	return s.repo.GetLikesByThread(threadID)
+func (s *LikeService) GetLikesByComment(commentID, viewerID uuid.UUID) ([]*model.Like, error) {
+	if _, err := s.checkTarget(model.LikeTargetComment, commentID, viewerID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetLikesByTarget(model.LikeTargetComment, commentID)
 }
 
 func (s *LikeService) GetLikesByUser(userID uuid.UUID) ([]*model.Like, error) {
 	return s.repo.GetLikesByUser(userID)
 }
+
+func (s *LikeService) GetReactionSummary(targetType string, targetID uuid.UUID, viewerID uuid.UUID) (*model.ReactionSummary, error) {
+	if _, err := s.checkTarget(targetType, targetID, viewerID); err != nil {
+		return nil, err
+	}
+	counts, err := s.repo.CountReactions(targetType, targetID)
+	if err != nil {
+		return nil, err
+	}
+	mine, err := s.repo.GetUserReaction(targetType, targetID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	summary := &model.ReactionSummary{
+		TargetType: targetType,
+		TargetID:   targetID,
+		Counts:     counts,
+		MyReaction: mine,
+	}
+	for _, n := range counts {
+		summary.Total += n
+	}
+	return summary, nil
+}
+
+// checkTarget hides likes on threads, and on comments of threads, that the
+// viewer is not allowed to see, and returns the thread the target belongs to.
+func (s *LikeService) checkTarget(targetType string, targetID uuid.UUID, viewerID uuid.UUID) (*model.Thread, error) {
+	threadID := targetID
+	if targetType == model.LikeTargetComment {
+		comment, err := s.comments.GetByID(targetID)
+		if err != nil || comment.IsDeleted {
+			return nil, ErrThreadNotFound
+		}
+		threadID = comment.ThreadID
+	}
+	return s.access.Visible(threadID, viewerID)
+}
+
+func (s *LikeService) AllowedReactions() []string {
+	return s.reactions
+}
+
+func (s *LikeService) isAllowed(reaction string) bool {
+	for _, r := range s.reactions {
+		if r == reaction {
+			return true
+		}
+	}
+	return false
+}
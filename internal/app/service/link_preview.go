@@ -0,0 +1,274 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"context"
+	"errors"
+	"fmt"
+	"golang.org/x/net/html"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	previewFetchTimeout = 5 * time.Second
+	previewMaxBytes     = 512 << 10
+	previewMaxRedirects = 3
+	previewCacheTTL     = 24 * time.Hour
+	previewQueueSize    = 256
+	previewMaxFieldLen  = 500
+)
+
+var (
+	ErrPreviewAddressBlocked = errors.New("link preview address is not allowed")
+	ErrPreviewNotHTML        = errors.New("link preview target is not an HTML page")
+)
+
+var urlPattern = regexp.MustCompile(`https?://[^\s<>()"'\[\]]+`)
+
+// FirstURL returns the first http(s) link in content, or "".
+func FirstURL(content string) string {
+	u := urlPattern.FindString(content)
+	return strings.TrimRight(u, ".,;:!?")
+}
+
+// blockedNetworks lists ranges that are not covered by the net.IP helpers but
+// must not be reachable from the fetcher either.
+var blockedNetworks = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{"100.64.0.0/10", "192.0.0.0/24", "198.18.0.0/15", "64:ff9b::/96"} {
+		_, n, _ := net.ParseCIDR(cidr)
+		nets = append(nets, n)
+	}
+	return nets
+}()
+
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, n := range blockedNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewPreviewHTTPClient returns the client used to unfurl links in production.
+// Every connection, including ones made while following redirects, is
+// checked after DNS resolution, so a hostname that resolves to a private
+// address is refused as well.
+func NewPreviewHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: previewFetchTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, port, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || !publicIP(ip) || (port != "80" && port != "443") {
+				return ErrPreviewAddressBlocked
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: previewFetchTimeout,
+		Transport: &http.Transport{
+			Proxy:                 nil,
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   previewFetchTimeout,
+			ResponseHeaderTimeout: previewFetchTimeout,
+			MaxIdleConns:          10,
+			IdleConnTimeout:       30 * time.Second,
+		},
+	}
+}
+
+// LinkPreviewFetcher downloads a page and extracts its OpenGraph and Twitter
+// card metadata. The client is injectable so the fetcher can be pointed at a
+// local test server; NewPreviewHTTPClient provides the guarded one.
+type LinkPreviewFetcher struct {
+	client  *http.Client
+	timeout time.Duration
+}
+
+func NewLinkPreviewFetcher(client *http.Client) *LinkPreviewFetcher {
+	return &LinkPreviewFetcher{client: client, timeout: previewFetchTimeout}
+}
+
+func (f *LinkPreviewFetcher) Fetch(ctx context.Context, rawURL string) (*model.LinkPreview, error) {
+	page, err := url.Parse(rawURL)
+	if err != nil || (page.Scheme != "http" && page.Scheme != "https") || page.Host == "" {
+		return nil, ErrPreviewAddressBlocked
+	}
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("User-Agent", "WebMessangerBot/1.0 (+link preview)")
+
+	client := *f.client
+	client.CheckRedirect = func(r *http.Request, via []*http.Request) error {
+		if len(via) > previewMaxRedirects {
+			return fmt.Errorf("stopped after %d redirects", previewMaxRedirects)
+		}
+		if r.URL.Scheme != "http" && r.URL.Scheme != "https" {
+			return ErrPreviewAddressBlocked
+		}
+		return nil
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("link preview fetch returned %s", resp.Status)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mediaType != "text/html" {
+		return nil, ErrPreviewNotHTML
+	}
+
+	preview := parsePreview(io.LimitReader(resp.Body, previewMaxBytes), resp.Request.URL)
+	preview.URL = rawURL
+	preview.FetchedAt = time.Now()
+	return preview, nil
+}
+
+// parsePreview reads meta tags from the document head. OpenGraph values win
+// over Twitter card values, which win over <title> and meta description.
+func parsePreview(r io.Reader, base *url.URL) *model.LinkPreview {
+	meta := map[string]string{}
+	var title string
+	z := html.NewTokenizer(r)
+	inTitle := false
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		tok := z.Token()
+		switch {
+		case tt == html.StartTagToken && tok.Data == "title":
+			inTitle = true
+		case tt == html.EndTagToken && tok.Data == "title":
+			inTitle = false
+		case tt == html.TextToken && inTitle && title == "":
+			title = tok.Data
+		case (tt == html.StartTagToken || tt == html.SelfClosingTagToken) && tok.Data == "meta":
+			var key, content string
+			for _, a := range tok.Attr {
+				switch a.Key {
+				case "property", "name":
+					key = strings.ToLower(a.Val)
+				case "content":
+					content = a.Val
+				}
+			}
+			if _, seen := meta[key]; key != "" && !seen {
+				meta[key] = content
+			}
+		case tt == html.StartTagToken && tok.Data == "body":
+			// Metadata lives in the head; stop before reading the page body.
+			return buildPreview(meta, title, base)
+		}
+	}
+	return buildPreview(meta, title, base)
+}
+
+func buildPreview(meta map[string]string, title string, base *url.URL) *model.LinkPreview {
+	pick := func(keys ...string) string {
+		for _, k := range keys {
+			if v := strings.TrimSpace(meta[k]); v != "" {
+				return truncateRunes(v, previewMaxFieldLen)
+			}
+		}
+		return ""
+	}
+	preview := &model.LinkPreview{
+		Title:       pick("og:title", "twitter:title"),
+		Description: pick("og:description", "twitter:description", "description"),
+		SiteName:    pick("og:site_name"),
+	}
+	if preview.Title == "" {
+		preview.Title = truncateRunes(strings.TrimSpace(title), previewMaxFieldLen)
+	}
+	if img := pick("og:image", "og:image:url", "twitter:image", "twitter:image:src"); img != "" {
+		if u, err := base.Parse(img); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+			preview.ImageURL = u.String()
+		}
+	}
+	return preview
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
+
+// LinkPreviewWorker unfurls links in the background. Results are cached by
+// URL, so a link that many threads share is fetched once per TTL.
+type LinkPreviewWorker struct {
+	fetcher *LinkPreviewFetcher
+	repo    usecase.LinkPreviewRepository
+	queue   chan string
+}
+
+func NewLinkPreviewWorker(fetcher *LinkPreviewFetcher, repo usecase.LinkPreviewRepository) *LinkPreviewWorker {
+	return &LinkPreviewWorker{fetcher: fetcher, repo: repo, queue: make(chan string, previewQueueSize)}
+}
+
+// Enqueue schedules url for unfurling without blocking the caller. When the
+// queue is full the link is skipped; the thread is simply shown without a
+// preview.
+func (w *LinkPreviewWorker) Enqueue(url string) {
+	select {
+	case w.queue <- url:
+	default:
+		log.Println("Link preview queue is full, skipping", url)
+	}
+}
+
+func (w *LinkPreviewWorker) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case u := <-w.queue:
+			w.unfurl(ctx, u)
+		}
+	}
+}
+
+func (w *LinkPreviewWorker) unfurl(ctx context.Context, url string) {
+	if cached, err := w.repo.Get(url); err == nil && time.Since(cached.FetchedAt) < previewCacheTTL {
+		return
+	}
+	preview, err := w.fetcher.Fetch(ctx, url)
+	if err != nil {
+		log.Println("Failed to fetch link preview for", url, ":", err)
+		preview = &model.LinkPreview{URL: url, Failed: true, FetchedAt: time.Now()}
+	}
+	if err := w.repo.Save(preview); err != nil {
+		log.Println("Failed to save link preview for", url, ":", err)
+	}
+}
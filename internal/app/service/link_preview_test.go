@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLinkPreviewFetcherParsesMetadata(t *testing.T) {
+	tests := []struct {
+		name  string
+		page  string
+		title string
+		desc  string
+		site  string
+		image string
+	}{
+		{
+			name: "opengraph",
+			page: `<html><head><title>Fallback</title>
+				<meta property="og:title" content="OG title">
+				<meta property="og:description" content="OG description">
+				<meta property="og:site_name" content="Example">
+				<meta property="og:image" content="/img/cover.png">
+				</head><body>body</body></html>`,
+			title: "OG title", desc: "OG description", site: "Example", image: "/img/cover.png",
+		},
+		{
+			name: "twitter card",
+			page: `<html><head>
+				<meta name="twitter:title" content="Card title">
+				<meta name="twitter:description" content="Card description">
+				<meta name="twitter:image" content="https://cdn.example.com/card.png">
+				</head></html>`,
+			title: "Card title", desc: "Card description", image: "https://cdn.example.com/card.png",
+		},
+		{
+			name:  "opengraph wins over twitter",
+			page:  `<head><meta name="twitter:title" content="Card"><meta property="og:title" content="OG"></head>`,
+			title: "OG",
+		},
+		{
+			name:  "title and meta description",
+			page:  `<html><head><title> Plain page </title><meta name="description" content="About it"></head></html>`,
+			title: "Plain page", desc: "About it",
+		},
+		{
+			name:  "first value wins",
+			page:  `<head><meta property="og:title" content="First"><meta property="og:title" content="Second"></head>`,
+			title: "First",
+		},
+		{
+			name:  "meta in body ignored",
+			page:  `<html><head><title>Head</title></head><body><meta property="og:title" content="Body"></body></html>`,
+			title: "Head",
+		},
+		{
+			name:  "non-http image dropped",
+			page:  `<head><meta property="og:title" content="T"><meta property="og:image" content="javascript:alert(1)"></head>`,
+			title: "T",
+		},
+		{
+			name:  "long title truncated",
+			page:  `<head><meta property="og:title" content="` + strings.Repeat("x", previewMaxFieldLen+10) + `"></head>`,
+			title: strings.Repeat("x", previewMaxFieldLen),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				fmt.Fprint(w, tt.page)
+			}))
+			defer srv.Close()
+
+			preview, err := NewLinkPreviewFetcher(srv.Client()).Fetch(context.Background(), srv.URL+"/page")
+			if err != nil {
+				t.Fatal(err)
+			}
+			image := tt.image
+			if strings.HasPrefix(image, "/") {
+				image = srv.URL + image
+			}
+			if preview.Title != tt.title || preview.Description != tt.desc || preview.SiteName != tt.site || preview.ImageURL != image {
+				t.Errorf("got title=%q desc=%q site=%q image=%q, want %q %q %q %q",
+					preview.Title, preview.Description, preview.SiteName, preview.ImageURL, tt.title, tt.desc, tt.site, image)
+			}
+			if preview.URL != srv.URL+"/page" {
+				t.Errorf("URL = %q", preview.URL)
+			}
+		})
+	}
+}
+
+func TestLinkPreviewFetcherRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		wantErr error
+	}{
+		{
+			name: "not html",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{}`)
+			},
+			wantErr: ErrPreviewNotHTML,
+		},
+		{
+			name: "error status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.NotFound(w, r)
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+			_, err := NewLinkPreviewFetcher(srv.Client()).Fetch(context.Background(), srv.URL)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("got %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	for _, raw := range []string{"ftp://example.com/", "file:///etc/passwd", "http://", "not a url"} {
+		if _, err := NewLinkPreviewFetcher(http.DefaultClient).Fetch(context.Background(), raw); !errors.Is(err, ErrPreviewAddressBlocked) {
+			t.Errorf("Fetch(%q) = %v, want ErrPreviewAddressBlocked", raw, err)
+		}
+	}
+}
+
+func TestLinkPreviewFetcherSizeCap(t *testing.T) {
+	padding := "<meta name=\"x\" content=\"" + strings.Repeat("a", previewMaxBytes) + "\">"
+	tests := []struct {
+		name  string
+		page  string
+		title string
+	}{
+		{"metadata before the cap", `<head><meta property="og:title" content="Early">` + padding + `</head>`, "Early"},
+		{"metadata past the cap", `<head>` + padding + `<meta property="og:title" content="Late"></head>`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/html")
+				fmt.Fprint(w, tt.page)
+			}))
+			defer srv.Close()
+			preview, err := NewLinkPreviewFetcher(srv.Client()).Fetch(context.Background(), srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if preview.Title != tt.title {
+				t.Errorf("title = %q, want %q", preview.Title, tt.title)
+			}
+		})
+	}
+}
+
+func TestLinkPreviewFetcherRedirectLimit(t *testing.T) {
+	// /hop/n redirects to /hop/n-1 until /hop/0 serves the page.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/hop/"))
+		if n > 0 {
+			http.Redirect(w, r, "/hop/"+strconv.Itoa(n-1), http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, `<head><title>Landed</title></head>`)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		hops    int
+		wantErr bool
+	}{
+		{0, false},
+		{previewMaxRedirects, false},
+		{previewMaxRedirects + 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.hops), func(t *testing.T) {
+			preview, err := NewLinkPreviewFetcher(srv.Client()).Fetch(context.Background(), srv.URL+"/hop/"+strconv.Itoa(tt.hops))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if preview.Title != "Landed" {
+				t.Errorf("title = %q", preview.Title)
+			}
+		})
+	}
+}
+
+func TestLinkPreviewFetcherTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	fetcher := NewLinkPreviewFetcher(srv.Client())
+	fetcher.timeout = 50 * time.Millisecond
+	start := time.Now()
+	_, err := fetcher.Fetch(context.Background(), srv.URL)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("fetch took %v", elapsed)
+	}
+}
+
+func TestPreviewHTTPClientBlocksPrivateAddresses(t *testing.T) {
+	client := NewPreviewHTTPClient()
+	for _, target := range []string{
+		"http://127.0.0.1/",
+		"http://[::1]/",
+		"http://10.0.0.1/",
+		"https://172.16.0.1/",
+		"http://192.168.1.1/",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://100.64.0.1/",
+		"http://0.0.0.0/",
+		"http://localhost/",
+	} {
+		t.Run(target, func(t *testing.T) {
+			resp, err := client.Get(target)
+			if err == nil {
+				resp.Body.Close()
+				t.Fatal("request was not refused")
+			}
+			if !errors.Is(err, ErrPreviewAddressBlocked) {
+				t.Errorf("got %v, want ErrPreviewAddressBlocked", err)
+			}
+		})
+	}
+}
+
+func TestPreviewHTTPClientBlocksLoopbackServer(t *testing.T) {
+	var hit bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer srv.Close()
+
+	_, err := NewLinkPreviewFetcher(NewPreviewHTTPClient()).Fetch(context.Background(), srv.URL)
+	if !errors.Is(err, ErrPreviewAddressBlocked) {
+		t.Errorf("got %v, want ErrPreviewAddressBlocked", err)
+	}
+	if hit {
+		t.Error("request reached the loopback server")
+	}
+}
+
+func TestPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.31.255.255", false},
+		{"192.168.0.1", false},
+		{"fd00::1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"0.0.0.0", false},
+		{"224.0.0.1", false},
+		{"100.64.0.1", false},
+		{"198.18.0.1", false},
+		{"64:ff9b::7f00:1", false},
+	}
+	for _, tt := range tests {
+		if got := publicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("publicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
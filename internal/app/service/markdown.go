@@ -0,0 +1,340 @@
+package service
+
+import (
+	"golang.org/x/net/html"
+	"io"
+	"log"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// Markdown support is deliberately small: **bold**, *italics*, `code`,
+// fenced code blocks, [links](https://...), - and 1. lists and > quotes.
+// Everything else is escaped and shown as typed.
+
+const (
+	maxMarkdownNesting = 8
+	renderBatchSize    = 200
+)
+
+// allowedTags lists the only elements RenderMarkdown may emit, with the
+// attributes each of them may carry.
+var allowedTags = map[string][]string{
+	"p":          nil,
+	"br":         nil,
+	"strong":     nil,
+	"em":         nil,
+	"code":       nil,
+	"pre":        nil,
+	"ul":         nil,
+	"ol":         nil,
+	"li":         nil,
+	"blockquote": nil,
+	"a":          {"href", "rel", "target"},
+}
+
+// RenderMarkdown converts thread or comment content to HTML that is safe to
+// insert into a page as is.
+func RenderMarkdown(src string) string {
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(src, "\n"), 0)
+	return SanitizeHTML(b.String())
+}
+
+// BackfillContentHTML fills in the cached HTML of threads and comments that
+// were written before rendering existed. It runs once at startup.
+func BackfillContentHTML(threads *ThreadService, comments *CommentService) {
+	for _, render := range []func(int) (int, error){threads.RenderMissingHTML, comments.RenderMissingHTML} {
+		for {
+			n, err := render(renderBatchSize)
+			if err != nil {
+				log.Println("Failed to render content HTML:", err)
+				break
+			}
+			if n < renderBatchSize {
+				break
+			}
+		}
+	}
+}
+
+func renderBlocks(b *strings.Builder, lines []string, depth int) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			i++
+		case strings.HasPrefix(trimmed, "```"):
+			i++
+			var code []string
+			for i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```") {
+				code = append(code, lines[i])
+				i++
+			}
+			i++ // closing fence
+			b.WriteString("<pre><code>")
+			b.WriteString(html.EscapeString(strings.Join(code, "\n")))
+			b.WriteString("</code></pre>")
+		case strings.HasPrefix(trimmed, ">") && depth < maxMarkdownNesting:
+			var quoted []string
+			for i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">") {
+				q := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quoted = append(quoted, strings.TrimPrefix(q, " "))
+				i++
+			}
+			b.WriteString("<blockquote>")
+			renderBlocks(b, quoted, depth+1)
+			b.WriteString("</blockquote>")
+		case listItem(trimmed, false) != "":
+			i = renderList(b, lines, i, false)
+		case listItem(trimmed, true) != "":
+			i = renderList(b, lines, i, true)
+		default:
+			var para []string
+			for i < len(lines) && startsParagraphLine(lines[i]) {
+				para = append(para, renderInline(strings.TrimSpace(lines[i]), 0))
+				i++
+			}
+			b.WriteString("<p>")
+			b.WriteString(strings.Join(para, "<br>"))
+			b.WriteString("</p>")
+		}
+	}
+}
+
+// startsParagraphLine reports whether line continues a paragraph rather than
+// ending it or opening another block.
+func startsParagraphLine(line string) bool {
+	t := strings.TrimSpace(line)
+	return t != "" && !strings.HasPrefix(t, "```") && !strings.HasPrefix(t, ">") &&
+		listItem(t, false) == "" && listItem(t, true) == ""
+}
+
+func renderList(b *strings.Builder, lines []string, i int, ordered bool) int {
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+	}
+	b.WriteString("<" + tag + ">")
+	for i < len(lines) {
+		item := listItem(strings.TrimSpace(lines[i]), ordered)
+		if item == "" {
+			break
+		}
+		b.WriteString("<li>")
+		b.WriteString(renderInline(item, 0))
+		b.WriteString("</li>")
+		i++
+	}
+	b.WriteString("</" + tag + ">")
+	return i
+}
+
+// listItem returns the text of a "- item" (or "1. item" when ordered) line,
+// or "" when the line is not a list item of that kind.
+func listItem(line string, ordered bool) string {
+	if !ordered {
+		if len(line) > 2 && (line[0] == '-' || line[0] == '*' || line[0] == '+') && line[1] == ' ' {
+			return strings.TrimSpace(line[2:])
+		}
+		return ""
+	}
+	n := 0
+	for n < len(line) && n < 9 && line[n] >= '0' && line[n] <= '9' {
+		n++
+	}
+	if n == 0 || n+2 > len(line) || (line[n] != '.' && line[n] != ')') || line[n+1] != ' ' {
+		return ""
+	}
+	return strings.TrimSpace(line[n+2:])
+}
+
+func renderInline(s string, depth int) string {
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		rest := s[i:]
+		if depth < maxMarkdownNesting {
+			switch {
+			case rest[0] == '`':
+				if end := strings.IndexByte(rest[1:], '`'); end > 0 {
+					b.WriteString("<code>" + html.EscapeString(rest[1:1+end]) + "</code>")
+					i += end + 2
+					continue
+				}
+			case strings.HasPrefix(rest, "***") || strings.HasPrefix(rest, "___"):
+				if inner, n := delimited(s, i, rest[:3]); n > 0 {
+					b.WriteString("<strong><em>" + renderInline(inner, depth+1) + "</em></strong>")
+					i += n
+					continue
+				}
+				if inner, n := delimited(s, i, rest[:2]); n > 0 {
+					b.WriteString("<strong>" + renderInline(inner, depth+1) + "</strong>")
+					i += n
+					continue
+				}
+			case strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__"):
+				if inner, n := delimited(s, i, rest[:2]); n > 0 {
+					b.WriteString("<strong>" + renderInline(inner, depth+1) + "</strong>")
+					i += n
+					continue
+				}
+			case rest[0] == '*' || rest[0] == '_':
+				if inner, n := delimited(s, i, rest[:1]); n > 0 {
+					b.WriteString("<em>" + renderInline(inner, depth+1) + "</em>")
+					i += n
+					continue
+				}
+			case rest[0] == '[':
+				if text, href, n := parseLink(rest); n > 0 {
+					if safeURL(href) {
+						b.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow noopener noreferrer" target="_blank">`)
+						b.WriteString(renderInline(text, depth+1))
+						b.WriteString("</a>")
+					} else {
+						b.WriteString(renderInline(text, depth+1))
+					}
+					i += n
+					continue
+				}
+			}
+		}
+		b.WriteString(html.EscapeString(rest[:1]))
+		i++
+	}
+	return b.String()
+}
+
+// delimited finds the span opened by delim at s[i:]. It returns the inner
+// text and the number of bytes consumed, or 0 when the delimiter is not
+// closed or the span is empty or padded with spaces. Underscores only count
+// at word boundaries so snake_case names are left alone.
+func delimited(s string, i int, delim string) (string, int) {
+	if delim[0] == '_' && i > 0 && isWordByte(s[i-1]) {
+		return "", 0
+	}
+	body := s[i+len(delim):]
+	end := strings.Index(body, delim)
+	if len(delim) == 1 {
+		// Skip doubled delimiters, they belong to a nested bold span.
+		for end >= 0 && end+1 < len(body) && body[end+1] == delim[0] {
+			next := strings.Index(body[end+2:], delim)
+			if next < 0 {
+				end = -1
+				break
+			}
+			end += 2 + next
+		}
+	}
+	if end <= 0 {
+		return "", 0
+	}
+	inner := body[:end]
+	if strings.TrimSpace(inner) != inner {
+		return "", 0
+	}
+	after := i + len(delim) + end + len(delim)
+	if delim[0] == '_' && after < len(s) && isWordByte(s[after]) {
+		return "", 0
+	}
+	return inner, after - i
+}
+
+// parseLink parses "[text](url)" at the start of s.
+func parseLink(s string) (text, href string, n int) {
+	closeText := strings.Index(s, "](")
+	if closeText <= 1 {
+		return "", "", 0
+	}
+	closeURL := strings.IndexByte(s[closeText+2:], ')')
+	if closeURL <= 0 {
+		return "", "", 0
+	}
+	href = strings.TrimSpace(s[closeText+2 : closeText+2+closeURL])
+	if strings.ContainsAny(href, " \t") {
+		return "", "", 0
+	}
+	return s[1:closeText], href, closeText + 3 + closeURL
+}
+
+func safeURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		return u.Host != ""
+	case "mailto":
+		return u.Opaque != ""
+	}
+	return false
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= 0x80 || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+}
+
+// SanitizeHTML drops every element and attribute outside allowedTags, along
+// with the contents of script and style elements. It runs over the renderer's
+// own output as a second line of defence.
+func SanitizeHTML(fragment string) string {
+	var b strings.Builder
+	z := html.NewTokenizer(strings.NewReader(fragment))
+	skip := 0
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return b.String()
+			}
+			return html.EscapeString(fragment)
+		case html.TextToken:
+			if skip == 0 {
+				b.WriteString(html.EscapeString(string(z.Text())))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			if tok.Data == "script" || tok.Data == "style" {
+				if tok.Type == html.StartTagToken {
+					skip++
+				}
+				continue
+			}
+			attrs, ok := allowedTags[tok.Data]
+			if !ok || skip > 0 {
+				continue
+			}
+			b.WriteString("<" + tok.Data)
+			for _, a := range tok.Attr {
+				if !containsString(attrs, a.Key) || (a.Key == "href" && !safeURL(a.Val)) {
+					continue
+				}
+				b.WriteString(" " + a.Key + `="` + html.EscapeString(a.Val) + `"`)
+			}
+			b.WriteString(">")
+		case html.EndTagToken:
+			tok := z.Token()
+			if tok.Data == "script" || tok.Data == "style" {
+				if skip > 0 {
+					skip--
+				}
+				continue
+			}
+			if _, ok := allowedTags[tok.Data]; ok && skip == 0 && tok.Data != "br" {
+				b.WriteString("</" + tok.Data + ">")
+			}
+		}
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
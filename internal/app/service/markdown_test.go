@@ -0,0 +1,129 @@
+package service
+
+import (
+	"golang.org/x/net/html"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"paragraph", "hello\nworld", "<p>hello<br>world</p>"},
+		{"emphasis", "**bold** *it* `x<y`", "<p><strong>bold</strong> <em>it</em> <code>x&lt;y</code></p>"},
+		{"snake case left alone", "a_b_c", "<p>a_b_c</p>"},
+		{"link", "[site](https://example.com/?a=1&b=2)", `<p><a href="https://example.com/?a=1&amp;b=2" rel="nofollow noopener noreferrer" target="_blank">site</a></p>`},
+		{"mailto link", "[mail](mailto:a@example.com)", `<p><a href="mailto:a@example.com" rel="nofollow noopener noreferrer" target="_blank">mail</a></p>`},
+		{"list", "- a\n- b", "<ul><li>a</li><li>b</li></ul>"},
+		{"ordered list", "1. a\n2) b", "<ol><li>a</li><li>b</li></ol>"},
+		{"quote", "> a\n> b", "<blockquote><p>a<br>b</p></blockquote>"},
+		{"fenced code", "```\n<b>\n```", "<pre><code>&lt;b&gt;</code></pre>"},
+		{"unclosed fence", "```\n**x**", "<pre><code>**x**</code></pre>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderMarkdown(tt.src); got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// assertSafeHTML parses got the way a browser would and fails on any element,
+// attribute or link that RenderMarkdown must not emit.
+func assertSafeHTML(t *testing.T, got string) {
+	t.Helper()
+	z := html.NewTokenizer(strings.NewReader(got))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() != io.EOF {
+				t.Errorf("unparsable output %s: %v", got, z.Err())
+			}
+			return
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tok := z.Token()
+			attrs, ok := allowedTags[tok.Data]
+			if !ok {
+				t.Errorf("output has a <%s> element: %s", tok.Data, got)
+			}
+			for _, a := range tok.Attr {
+				if !containsString(attrs, a.Key) {
+					t.Errorf("output has a %s attribute on <%s>: %s", a.Key, tok.Data, got)
+				}
+				if a.Key == "href" && !safeURL(a.Val) {
+					t.Errorf("output links to %q: %s", a.Val, got)
+				}
+			}
+		}
+	}
+}
+
+func TestRenderMarkdownXSS(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		// The link ends at the first ")", so the rest is left as text.
+		{"javascript link", "[click](javascript:alert(1))", "<p>click)</p>"},
+		{"javascript link mixed case", "[click](JaVaScRiPt:alert(1))", "<p>click)</p>"},
+		{"javascript link with entity", "[click](javascript&#58;alert(1))", "<p>click)</p>"},
+		{"javascript link with leading space", "[click]( javascript:alert(1))", "<p>click)</p>"},
+		{"data link", "[click](data:text/html;base64,PHNjcmlwdD4=)", "<p>click</p>"},
+		{"vbscript link", "[click](vbscript:msgbox)", "<p>click</p>"},
+		{"protocol-relative link", "[click](//evil.example)", "<p>click</p>"},
+		{"raw script", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		{"raw script in code", "`<script>`", "<p><code>&lt;script&gt;</code></p>"},
+		{"raw img", `<img src=x onerror=alert(1)>`, "<p>&lt;img src=x onerror=alert(1)&gt;</p>"},
+		{"quote breaks out of href", `[x](https://example.com/"onmouseover="alert(1))`, `<p><a href="https://example.com/&#34;onmouseover=&#34;alert(1" rel="nofollow noopener noreferrer" target="_blank">x</a>)</p>`},
+		{"tag in link text", "[<img src=x onerror=alert(1)>](https://example.com)", `<p><a href="https://example.com" rel="nofollow noopener noreferrer" target="_blank">&lt;img src=x onerror=alert(1)&gt;</a></p>`},
+		{"script in list", "- <script>x</script>", "<ul><li>&lt;script&gt;x&lt;/script&gt;</li></ul>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderMarkdown(tt.src)
+			assertSafeHTML(t, got)
+			if got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"allowed tags kept", "<p><strong>a</strong><br></p>", "<p><strong>a</strong><br></p>"},
+		{"script dropped with contents", "<p>a<script>alert(1)</script>b</p>", "<p>ab</p>"},
+		// Script contents are raw text, so the first end tag closes it, as in a
+		// browser; what follows is plain text.
+		{"nested script", "<script><script>x</script>y</script>z", "yz"},
+		{"style dropped with contents", "<style>p{}</style>ok", "ok"},
+		{"unknown tags dropped", `<img src=x onerror=alert(1)><iframe src="https://evil.example"></iframe>t`, "t"},
+		{"event handler dropped", `<p onclick="alert(1)">a</p>`, "<p>a</p>"},
+		{"style attribute dropped", `<strong style="background:url(javascript:alert(1))">a</strong>`, "<strong>a</strong>"},
+		{"javascript href dropped", `<a href="javascript:alert(1)">a</a>`, "<a>a</a>"},
+		{"entity-encoded javascript href dropped", `<a href="&#106;avascript:alert(1)">a</a>`, "<a>a</a>"},
+		{"whitespace-prefixed javascript href dropped", "<a href=\" \tjavascript:alert(1)\">a</a>", "<a>a</a>"},
+		{"safe href kept and escaped", `<a href="https://example.com/?q=&quot;x&quot;" title="t">a</a>`, `<a href="https://example.com/?q=&#34;x&#34;">a</a>`},
+		{"svg dropped", `<svg onload=alert(1)><p>x</p></svg>`, "<p>x</p>"},
+		{"text escaped", "a < b & c", "a &lt; b &amp; c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SanitizeHTML(tt.in)
+			assertSafeHTML(t, got)
+			if got != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/gabriel-vasile/mimetype"
+	"github.com/google/uuid"
+	"io"
+	"strings"
+)
+
+const (
+	MaxImageUploadSize  = 10 << 20
+	MaxVideoUploadSize  = 50 << 20
+	MaxAvatarUploadSize = 2 << 20
+
+	// sniffLen is how much of the file is read to detect its real type.
+	sniffLen = 3072
+)
+
+var (
+	ErrUnsupportedMedia = errors.New("unsupported media type")
+	ErrMediaTooLarge    = errors.New("file is too large")
+	ErrInvalidMediaKind = errors.New("unknown upload kind")
+)
+
+// allowedMedia maps each upload kind to the content types it accepts and
+// their size limits. Types are detected from the file bytes, never from the
+// client-supplied Content-Type or file name. Only formats the image pipeline
+// can decode are accepted as images; WebP is not among them, as the standard
+// library has no decoder for it.
+var allowedMedia = map[string]map[string]int64{
+	model.MediaKindThread: {
+		"image/jpeg": MaxImageUploadSize,
+		"image/png":  MaxImageUploadSize,
+		"image/gif":  MaxImageUploadSize,
+		"video/mp4":  MaxVideoUploadSize,
+		"video/webm": MaxVideoUploadSize,
+	},
+	model.MediaKindAvatar: {
+		"image/jpeg": MaxAvatarUploadSize,
+		"image/png":  MaxAvatarUploadSize,
+	},
+}
+
+// primaryVariant is the variant whose URL becomes the upload's URL, i.e. what
+// ends up in media_url or avatar_url.
+var primaryVariant = map[string]string{
+	model.MediaKindThread: VariantFull,
+	model.MediaKindAvatar: VariantAvatar256,
+}
+
+type MediaService struct {
+	storage  usecase.Storage
+	repo     usecase.MediaRepository
+	pipeline *ImagePipeline
+}
+
+func NewMediaService(storage usecase.Storage, repo usecase.MediaRepository, pipeline *ImagePipeline) *MediaService {
+	return &MediaService{storage: storage, repo: repo, pipeline: pipeline}
+}
+
+func (s *MediaService) Upload(ctx context.Context, kind string, userID uuid.UUID, file io.Reader, size int64) (*model.Media, error) {
+	allowed, ok := allowedMedia[kind]
+	if !ok {
+		return nil, ErrInvalidMediaKind
+	}
+	head := make([]byte, sniffLen)
+	n, err := io.ReadFull(file, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	head = head[:n]
+	detected := mimetype.Detect(head)
+	contentType, _, _ := strings.Cut(detected.String(), ";")
+	limit, ok := allowed[contentType]
+	if !ok {
+		return nil, ErrUnsupportedMedia
+	}
+	if size > limit {
+		return nil, ErrMediaTooLarge
+	}
+
+	body := io.MultiReader(bytes.NewReader(head), file)
+	base := kind + "s/" + userID.String() + "/" + uuid.New().String()
+	var media *model.Media
+	if strings.HasPrefix(contentType, "image/") {
+		media, err = s.storeImage(ctx, kind, base, body, size)
+	} else {
+		key := base + detected.Extension()
+		var url string
+		url, err = s.storage.Put(ctx, key, body, size, contentType)
+		media = &model.Media{URL: url, Kind: kind, ContentType: contentType, Size: size, Keys: []string{key}}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(userID, media); err != nil {
+		s.deleteFiles(ctx, media.Keys)
+		return nil, err
+	}
+	return media, nil
+}
+
+// Discard deletes a fresh upload that ended up unused, such as an avatar the
+// profile update then rejected.
+func (s *MediaService) Discard(ctx context.Context, media *model.Media) error {
+	if err := s.repo.Delete(media.URL); err != nil {
+		return err
+	}
+	return s.deleteFiles(ctx, media.Keys)
+}
+
+// deleteFiles removes every key, returning the first error.
+func (s *MediaService) deleteFiles(ctx context.Context, keys []string) error {
+	var first error
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// storeImage runs the upload through the image pipeline and stores every
+// variant; the original bytes are never stored.
+func (s *MediaService) storeImage(ctx context.Context, kind, base string, body io.Reader, size int64) (*model.Media, error) {
+	data, err := io.ReadAll(io.LimitReader(body, size))
+	if err != nil {
+		return nil, err
+	}
+	processed, err := s.pipeline.Process(ctx, kind, data)
+	if err != nil {
+		return nil, err
+	}
+	media := &model.Media{Kind: kind, Variants: map[string]string{}}
+	for name, variant := range processed.Data {
+		contentType := processed.ContentType[name]
+		ext := ".jpg"
+		if contentType == "image/gif" {
+			ext = ".gif"
+		}
+		key := base + "/" + name + ext
+		url, err := s.storage.Put(ctx, key, bytes.NewReader(variant), int64(len(variant)), contentType)
+		if err != nil {
+			s.deleteFiles(ctx, media.Keys)
+			return nil, err
+		}
+		media.Keys = append(media.Keys, key)
+		media.Variants[name] = url
+		if name == primaryVariant[kind] {
+			media.URL = url
+			media.ContentType = contentType
+			media.Size = int64(len(variant))
+			width, height := processed.Size[name].X, processed.Size[name].Y
+			media.Width, media.Height = &width, &height
+		}
+	}
+	return media, nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+)
+
+// fakeStorage keeps stored files in memory.
+type fakeStorage struct {
+	files map[string][]byte
+}
+
+func (s *fakeStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	s.files[key] = data
+	return "/media/" + key, nil
+}
+
+func (s *fakeStorage) Delete(ctx context.Context, key string) error {
+	delete(s.files, key)
+	return nil
+}
+
+func encodePNG(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, quadrants(64, 64)); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestMediaUpload(t *testing.T) {
+	pngData := encodePNG(t)
+	gifData := encodeGIF(t, 3, 8, 8)
+	mp4Data := append([]byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom"), make([]byte, 64)...)
+	tests := []struct {
+		name        string
+		kind        string
+		data        []byte
+		size        int64 // declared size; len(data) when zero
+		wantErr     error
+		contentType string
+		files       int
+	}{
+		{name: "avatar png", kind: model.MediaKindAvatar, data: pngData, contentType: "image/jpeg", files: 2},
+		{name: "thread png", kind: model.MediaKindThread, data: pngData, contentType: "image/jpeg", files: 2},
+		{name: "thread gif keeps animation", kind: model.MediaKindThread, data: gifData, contentType: "image/gif", files: 2},
+		{name: "thread mp4", kind: model.MediaKindThread, data: mp4Data, contentType: "video/mp4", files: 1},
+		{name: "avatar gif", kind: model.MediaKindAvatar, data: gifData, wantErr: ErrUnsupportedMedia},
+		{name: "avatar mp4", kind: model.MediaKindAvatar, data: mp4Data, wantErr: ErrUnsupportedMedia},
+		{name: "html", kind: model.MediaKindThread, data: []byte("<html><script>alert(1)</script></html>"), wantErr: ErrUnsupportedMedia},
+		{name: "text", kind: model.MediaKindThread, data: []byte(strings.Repeat("plain text ", 10)), wantErr: ErrUnsupportedMedia},
+		{name: "empty", kind: model.MediaKindThread, data: nil, wantErr: ErrUnsupportedMedia},
+		{name: "png truncated after header", kind: model.MediaKindThread, data: pngData[:64], wantErr: ErrUnsupportedMedia},
+		{name: "avatar over its limit", kind: model.MediaKindAvatar, data: pngData, size: MaxAvatarUploadSize + 1, wantErr: ErrMediaTooLarge},
+		{name: "image over its limit", kind: model.MediaKindThread, data: pngData, size: MaxImageUploadSize + 1, wantErr: ErrMediaTooLarge},
+		{name: "video under its limit", kind: model.MediaKindThread, data: mp4Data, size: MaxImageUploadSize + 1, contentType: "video/mp4", files: 1},
+		{name: "video over its limit", kind: model.MediaKindThread, data: mp4Data, size: MaxVideoUploadSize + 1, wantErr: ErrMediaTooLarge},
+		{name: "unknown kind", kind: "banner", data: pngData, wantErr: ErrInvalidMediaKind},
+	}
+	pipeline := NewImagePipeline(1)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage := &fakeStorage{files: map[string][]byte{}}
+			s := NewMediaService(storage, fakeMediaRepo{}, pipeline)
+			size := tt.size
+			if size == 0 {
+				size = int64(len(tt.data))
+			}
+			media, err := s.Upload(context.Background(), tt.kind, uuid.New(), bytes.NewReader(tt.data), size)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %v, want %v", err, tt.wantErr)
+				}
+				if len(storage.files) != 0 {
+					t.Errorf("%d files stored for a rejected upload", len(storage.files))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if media.ContentType != tt.contentType {
+				t.Errorf("content type = %q, want %q", media.ContentType, tt.contentType)
+			}
+			if len(storage.files) != tt.files || len(media.Keys) != tt.files {
+				t.Errorf("stored %d files with %d keys, want %d", len(storage.files), len(media.Keys), tt.files)
+			}
+			if err := s.Discard(context.Background(), media); err != nil {
+				t.Fatal(err)
+			}
+			if len(storage.files) != 0 {
+				t.Errorf("%d files left after discarding", len(storage.files))
+			}
+		})
+	}
+}
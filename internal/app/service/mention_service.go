@@ -0,0 +1,120 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"github.com/google/uuid"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_.@/])@([\p{L}\p{N}_.\-]+)`)
+
+// MentionService resolves @username mentions in thread and comment content,
+// stores them and notifies users the first time they are mentioned.
+type MentionService struct {
+	repo          usecase.MentionRepository
+	userRepo      usecase.UserRepository
+	notifications usecase.NotificationRepository
+}
+
+func NewMentionService(repo usecase.MentionRepository, userRepo usecase.UserRepository, notifications usecase.NotificationRepository) *MentionService {
+	return &MentionService{repo: repo, userRepo: userRepo, notifications: notifications}
+}
+
+// Index replaces the stored mentions of a thread or comment with the ones
+// found in content. With notify set, users mentioned for the first time get
+// a notification; unpublished content passes false and calls NotifyAll later.
+func (s *MentionService) Index(sourceType string, sourceID, threadID, actorID uuid.UUID, content string, notify bool) ([]*model.Mention, error) {
+	previous, err := s.repo.GetMentionedUserIDs(sourceType, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	mentions := s.resolve(content)
+	if err := s.repo.ReplaceForSource(sourceType, sourceID, mentions); err != nil {
+		return nil, err
+	}
+	if !notify {
+		return mentions, nil
+	}
+	userIDs := make([]uuid.UUID, 0, len(mentions))
+	for _, m := range mentions {
+		userIDs = append(userIDs, m.UserID)
+	}
+	if err := s.notify(sourceType, sourceID, threadID, actorID, userIDs, previous); err != nil {
+		return nil, err
+	}
+	return mentions, nil
+}
+
+// NotifyAll notifies every user currently mentioned by the source.
+func (s *MentionService) NotifyAll(sourceType string, sourceID, threadID, actorID uuid.UUID) error {
+	userIDs, err := s.repo.GetMentionedUserIDs(sourceType, sourceID)
+	if err != nil {
+		return err
+	}
+	return s.notify(sourceType, sourceID, threadID, actorID, userIDs, nil)
+}
+
+func (s *MentionService) notify(sourceType string, sourceID, threadID, actorID uuid.UUID, userIDs, skip []uuid.UUID) error {
+	notified := make(map[uuid.UUID]bool, len(skip)+1)
+	notified[actorID] = true
+	for _, id := range skip {
+		notified[id] = true
+	}
+	for _, userID := range userIDs {
+		if notified[userID] {
+			continue
+		}
+		notified[userID] = true
+		n := &model.Notification{
+			ID:        uuid.New(),
+			UserID:    userID,
+			ActorID:   actorID,
+			Type:      model.NotificationMention,
+			ThreadID:  &threadID,
+			CreatedAt: time.Now(),
+		}
+		if sourceType == model.MentionSourceComment {
+			n.CommentID = &sourceID
+		}
+		if err := s.notifications.Create(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MentionService) resolve(content string) []*model.Mention {
+	mentions := make([]*model.Mention, 0)
+	users := make(map[string]*model.User)
+	for _, idx := range mentionPattern.FindAllStringSubmatchIndex(content, -1) {
+		name := strings.TrimRight(content[idx[2]:idx[3]], ".-")
+		if n := utf8.RuneCountInString(name); n < model.MinNameLength || n > model.MaxNameLength {
+			continue
+		}
+		user, ok := users[name]
+		if !ok {
+			user, _ = s.userRepo.GetByName(name)
+			users[name] = user
+		}
+		if user == nil {
+			continue
+		}
+		start := idx[2] - 1
+		mentions = append(mentions, &model.Mention{
+			UserID:   user.ID,
+			UserName: user.Name,
+			Offset:   utf16Len(content[:start]),
+			Length:   utf16Len(content[start : idx[2]+len(name)]),
+		})
+	}
+	return mentions
+}
+
+func utf16Len(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"github.com/google/uuid"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMentionResolve(t *testing.T) {
+	users := &fakeUserRepo{names: map[string]*model.User{}}
+	for _, name := range []string{
+		"bob",
+		"Нурсултан",          // 9 letters, 18 bytes
+		"Айгерим_Нурланқызы", // 18 letters, too long to register
+		strings.Repeat("ә", model.MaxNameLength),
+		strings.Repeat("ә", model.MaxNameLength+1),
+		"al",
+	} {
+		users.names[name] = &model.User{ID: uuid.New(), Name: name}
+	}
+	s := NewMentionService(nil, users, nil)
+
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{"ascii", "hi @bob", []string{"bob"}},
+		{"cyrillic over 16 bytes", "сәлем @Нурсултан!", []string{"Нурсултан"}},
+		{"non-ascii at the length limit", "@" + strings.Repeat("ә", model.MaxNameLength), []string{strings.Repeat("ә", model.MaxNameLength)}},
+		{"non-ascii over the length limit", "@" + strings.Repeat("ә", model.MaxNameLength+1), nil},
+		{"too long", "@Айгерим_Нурланқызы", nil},
+		{"too short", "@al", nil},
+		{"trailing punctuation", "thanks @Нурсултан.", []string{"Нурсултан"}},
+		{"email is not a mention", "bob@bob.com", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, m := range s.resolve(tt.content) {
+				got = append(got, m.UserName)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+var (
+	ErrConversationNotFound = errors.New("conversation not found")
+	ErrMessageSelf          = errors.New("you cannot message yourself")
+	ErrUserBlocked          = errors.New("you cannot message this user")
+)
+
+// MessageService handles direct messages. A block in either direction stops
+// new conversations and messages, but both users keep their history.
+type MessageService struct {
+	repo     usecase.MessageRepository
+	blocks   usecase.BlockRepository
+	userRepo usecase.UserRepository
+}
+
+func NewMessageService(repo usecase.MessageRepository, blocks usecase.BlockRepository, userRepo usecase.UserRepository) *MessageService {
+	return &MessageService{repo: repo, blocks: blocks, userRepo: userRepo}
+}
+
+// StartConversation opens a conversation with another user, or returns the
+// existing one.
+func (s *MessageService) StartConversation(userID, otherID uuid.UUID) (*model.Conversation, error) {
+	if userID == otherID {
+		return nil, ErrMessageSelf
+	}
+	if _, err := s.userRepo.GetByID(otherID); err != nil {
+		return nil, ErrUserNotFound
+	}
+	if err := s.checkBlocked(userID, otherID); err != nil {
+		return nil, err
+	}
+	id, err := s.repo.GetOrCreateConversation(userID, otherID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetConversation(id, userID)
+}
+
+func (s *MessageService) GetConversations(userID uuid.UUID, limit, offset int) ([]*model.Conversation, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.GetConversations(userID, normalizePageSize(limit), offset)
+}
+
+func (s *MessageService) Send(conversationID, senderID uuid.UUID, content string) (*model.Message, error) {
+	conversation, err := s.conversation(conversationID, senderID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Blocked {
+		return nil, ErrUserBlocked
+	}
+	message := &model.Message{
+		ID:             uuid.New(),
+		ConversationID: conversationID,
+		SenderID:       senderID,
+		Content:        content,
+		CreatedAt:      time.Now(),
+	}
+	if err := ValidateMessage(message); err != nil {
+		return nil, err
+	}
+	if err := s.repo.AddMessage(message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// GetMessages returns a page of a conversation's history, newest first, and
+// the cursor for the next, older page ("" when there are no more).
+func (s *MessageService) GetMessages(conversationID, userID uuid.UUID, cursor string, limit int) ([]*model.Message, string, error) {
+	seq, ok, err := decodeSeqCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	var before *model.MessageCursor
+	if ok {
+		before = &model.MessageCursor{Seq: seq}
+	}
+	if _, err := s.conversation(conversationID, userID); err != nil {
+		return nil, "", err
+	}
+	limit = normalizePageSize(limit)
+	messages, err := s.repo.GetMessages(conversationID, before, limit+1)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(messages) <= limit {
+		return messages, "", nil
+	}
+	messages = messages[:limit]
+	last := messages[len(messages)-1]
+	return messages, encodeSeqCursor(last.Seq), nil
+}
+
+func (s *MessageService) MarkRead(conversationID, userID uuid.UUID) error {
+	if _, err := s.conversation(conversationID, userID); err != nil {
+		return err
+	}
+	return s.repo.MarkRead(conversationID, userID)
+}
+
+// conversation loads a conversation the user takes part in.
+func (s *MessageService) conversation(id, userID uuid.UUID) (*model.Conversation, error) {
+	conversation, err := s.repo.GetConversation(id, userID)
+	if err != nil {
+		return nil, ErrConversationNotFound
+	}
+	return conversation, nil
+}
+
+func (s *MessageService) checkBlocked(userID, otherID uuid.UUID) error {
+	blocked, err := s.blocks.IsBlocked(userID, otherID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrUserBlocked
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/google/uuid"
+	"testing"
+	"time"
+)
+
+// fakeMessageRepo holds a single conversation between two users. Other
+// methods are not expected to be called.
+type fakeMessageRepo struct {
+	usecase.MessageRepository
+	conversation *model.Conversation
+	members      [2]uuid.UUID
+	messages     []*model.Message
+	created      int
+}
+
+func (r *fakeMessageRepo) GetOrCreateConversation(startedBy, otherID uuid.UUID) (uuid.UUID, error) {
+	r.created++
+	return r.conversation.ID, nil
+}
+
+func (r *fakeMessageRepo) GetConversation(id, userID uuid.UUID) (*model.Conversation, error) {
+	if id != r.conversation.ID || (userID != r.members[0] && userID != r.members[1]) {
+		return nil, errors.New("no rows")
+	}
+	return r.conversation, nil
+}
+
+// AddMessage numbers messages in insert order, as the database does.
+func (r *fakeMessageRepo) AddMessage(message *model.Message) error {
+	message.Seq = int64(len(r.messages) + 1)
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func (r *fakeMessageRepo) GetMessages(conversationID uuid.UUID, before *model.MessageCursor, limit int) ([]*model.Message, error) {
+	var page []*model.Message
+	for i := len(r.messages) - 1; i >= 0 && len(page) < limit; i-- {
+		m := r.messages[i]
+		if before == nil || m.Seq < before.Seq {
+			page = append(page, m)
+		}
+	}
+	return page, nil
+}
+
+// fakeBlockRepo stores blocks as blocker -> blocked pairs.
+type fakeBlockRepo struct {
+	usecase.BlockRepository
+	blocks map[[2]uuid.UUID]bool
+}
+
+func (r *fakeBlockRepo) IsBlocked(userID, otherID uuid.UUID) (bool, error) {
+	return r.blocks[[2]uuid.UUID{userID, otherID}] || r.blocks[[2]uuid.UUID{otherID, userID}], nil
+}
+
+type fakeUserRepo struct {
+	usecase.UserRepository
+	users map[uuid.UUID]bool
+	names map[string]*model.User
+}
+
+func (r *fakeUserRepo) GetByName(name string) (*model.User, error) {
+	if u, ok := r.names[name]; ok {
+		return u, nil
+	}
+	return nil, errors.New("no rows")
+}
+
+func (r *fakeUserRepo) GetByID(id uuid.UUID) (*model.User, error) {
+	if !r.users[id] {
+		return nil, errors.New("no rows")
+	}
+	return &model.User{ID: id}, nil
+}
+
+func TestStartConversationBlocks(t *testing.T) {
+	alice, bob, carol := uuid.New(), uuid.New(), uuid.New()
+	tests := []struct {
+		name    string
+		blocks  [][2]uuid.UUID
+		from    uuid.UUID
+		to      uuid.UUID
+		wantErr error
+	}{
+		{name: "no block", from: alice, to: bob},
+		{name: "sender blocked recipient", blocks: [][2]uuid.UUID{{alice, bob}}, from: alice, to: bob, wantErr: ErrUserBlocked},
+		{name: "recipient blocked sender", blocks: [][2]uuid.UUID{{bob, alice}}, from: alice, to: bob, wantErr: ErrUserBlocked},
+		{name: "unrelated block", blocks: [][2]uuid.UUID{{carol, bob}}, from: alice, to: bob},
+		{name: "yourself", from: alice, to: alice, wantErr: ErrMessageSelf},
+		{name: "unknown user", from: alice, to: uuid.New(), wantErr: ErrUserNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks := &fakeBlockRepo{blocks: map[[2]uuid.UUID]bool{}}
+			for _, b := range tt.blocks {
+				blocks.blocks[b] = true
+			}
+			repo := &fakeMessageRepo{conversation: &model.Conversation{ID: uuid.New()}, members: [2]uuid.UUID{tt.from, tt.to}}
+			users := &fakeUserRepo{users: map[uuid.UUID]bool{alice: true, bob: true, carol: true}}
+			s := NewMessageService(repo, blocks, users)
+
+			conversation, err := s.StartConversation(tt.from, tt.to)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				if repo.created != 0 {
+					t.Error("conversation was created")
+				}
+				return
+			}
+			if conversation == nil || conversation.ID != repo.conversation.ID {
+				t.Errorf("got conversation %+v", conversation)
+			}
+		})
+	}
+}
+
+func TestSendChecksBlockAndMembership(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	tests := []struct {
+		name    string
+		blocked bool
+		sender  uuid.UUID
+		content string
+		wantErr error
+		invalid bool
+	}{
+		{name: "member sends", sender: alice, content: "hi"},
+		{name: "blocked conversation", blocked: true, sender: alice, content: "hi", wantErr: ErrUserBlocked},
+		{name: "blocked conversation checked before content", blocked: true, sender: alice, content: "", wantErr: ErrUserBlocked},
+		{name: "outsider", sender: uuid.New(), content: "hi", wantErr: ErrConversationNotFound},
+		{name: "empty message", sender: alice, content: " \n", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeMessageRepo{
+				conversation: &model.Conversation{ID: uuid.New(), Blocked: tt.blocked},
+				members:      [2]uuid.UUID{alice, bob},
+			}
+			s := NewMessageService(repo, &fakeBlockRepo{}, &fakeUserRepo{})
+
+			message, err := s.Send(repo.conversation.ID, tt.sender, tt.content)
+			if tt.invalid {
+				if codes := fieldCodes(t, err); codes["content"] != CodeRequired {
+					t.Errorf("got %v, want content %s", codes, CodeRequired)
+				}
+			} else if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if len(repo.messages) != 0 {
+					t.Error("message was stored")
+				}
+				return
+			}
+			if len(repo.messages) != 1 || repo.messages[0] != message || message.SenderID != tt.sender {
+				t.Errorf("stored %v, returned %+v", repo.messages, message)
+			}
+		})
+	}
+}
+
+func TestGetMessagesPagesBySeq(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	repo := &fakeMessageRepo{conversation: &model.Conversation{ID: uuid.New()}, members: [2]uuid.UUID{alice, bob}}
+	// Messages written by servers with skewed clocks: insert order and
+	// created_at disagree.
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, offset := range []int{0, -30, 5, -60, 2} {
+		repo.AddMessage(&model.Message{ID: uuid.New(), CreatedAt: start.Add(time.Duration(offset) * time.Second)})
+	}
+	s := NewMessageService(repo, &fakeBlockRepo{}, &fakeUserRepo{})
+
+	var got []*model.Message
+	cursor := ""
+	for pages := 0; pages < 10; pages++ {
+		page, next, err := s.GetMessages(repo.conversation.ID, bob, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, page...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+	if len(got) != len(repo.messages) {
+		t.Fatalf("got %d messages over all pages, want %d", len(got), len(repo.messages))
+	}
+	for i, m := range got {
+		if want := repo.messages[len(repo.messages)-1-i]; m != want {
+			t.Errorf("message %d has seq %d, want %d", i, m.Seq, want.Seq)
+		}
+	}
+	if _, _, err := s.GetMessages(repo.conversation.ID, uuid.New(), "", 2); !errors.Is(err, ErrConversationNotFound) {
+		t.Errorf("outsider: got %v, want ErrConversationNotFound", err)
+	}
+	if _, _, err := s.GetMessages(repo.conversation.ID, bob, encodeCursor(start, uuid.New()), 2); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("timestamp cursor: got %v, want ErrInvalidCursor", err)
+	}
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/google/uuid"
+	"time"
+)
+
+var (
+	ErrNotModerator          = errors.New("only the author or a moderator can do this")
+	ErrSensitiveLocked       = errors.New("a moderator marked this thread as sensitive")
+	ErrContentWarningTooLong = errors.New("content warning is too long")
+	ErrLockedByModerator     = errors.New("a moderator locked this thread")
+)
+
+// ModerationService holds the actions that moderators may take on other
+// people's content. Authors may take the same actions on their own threads,
+// and community moderators on the threads posted into their community.
+type ModerationService struct {
+	threads     usecase.ThreadRepository
+	users       usecase.UserRepository
+	communities usecase.CommunityRepository
+	audit       usecase.AuditRepository
+}
+
+func NewModerationService(threads usecase.ThreadRepository, users usecase.UserRepository, communities usecase.CommunityRepository, audit usecase.AuditRepository) *ModerationService {
+	return &ModerationService{threads: threads, users: users, communities: communities, audit: audit}
+}
+
+// SetSensitive marks a thread as sensitive or clears the flag. A flag set by
+// a moderator can only be cleared by a moderator.
+func (s *ModerationService) SetSensitive(threadID, actorID uuid.UUID, sensitive bool, contentWarning string) (*model.Thread, error) {
+	_, thread, canModerate, err := s.authorize(threadID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	isAuthor := thread.UserID == actorID
+	if !sensitive && thread.FlaggedByModerator && !canModerate {
+		return nil, ErrSensitiveLocked
+	}
+	thread.Sensitive = sensitive
+	thread.ContentWarning = contentWarning
+	var v validator
+	setSensitivity(&v, thread)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	thread.FlaggedByModerator = thread.Sensitive && (!isAuthor || thread.FlaggedByModerator)
+	if err := s.threads.SetSensitive(thread.ID, thread.Sensitive, thread.ContentWarning, thread.FlaggedByModerator); err != nil {
+		return nil, err
+	}
+	return thread, nil
+}
+
+// SetLocked locks or unlocks a thread and records the action in the audit
+// log. A lock placed by a moderator can only be lifted by a moderator.
+func (s *ModerationService) SetLocked(threadID, actorID uuid.UUID, locked bool) (*model.Thread, error) {
+	actor, thread, canModerate, err := s.authorize(threadID, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if thread.Locked == locked {
+		return thread, nil
+	}
+	lockedByModerator := thread.LockedBy != nil && *thread.LockedBy != thread.UserID
+	if !locked && lockedByModerator && !canModerate {
+		return nil, ErrLockedByModerator
+	}
+	action := model.AuditThreadUnlock
+	thread.Locked, thread.LockedBy, thread.LockedAt = false, nil, nil
+	if locked {
+		now := time.Now()
+		action = model.AuditThreadLock
+		thread.Locked, thread.LockedBy, thread.LockedAt = true, &actor.ID, &now
+	}
+	entry := &model.AuditEntry{
+		ID: uuid.New(), ActorID: &actor.ID, ActorName: actor.Name, Action: action,
+		TargetType: model.AuditTargetThread, TargetID: thread.ID, CreatedAt: time.Now(),
+	}
+	if err := s.threads.SetLocked(thread.ID, thread.Locked, thread.LockedBy, thread.LockedAt, entry); err != nil {
+		return nil, err
+	}
+	return thread, nil
+}
+
+// GetAuditLog returns the moderation history of a thread to its author and
+// its moderators.
+func (s *ModerationService) GetAuditLog(threadID, actorID uuid.UUID) ([]*model.AuditEntry, error) {
+	if _, _, _, err := s.authorize(threadID, actorID); err != nil {
+		return nil, err
+	}
+	return s.audit.GetByTarget(model.AuditTargetThread, threadID)
+}
+
+// CanRemoveThread fails unless actorID may delete the thread: its author, or
+// a moderator of it. Actors who may not and cannot see the thread get
+// ErrThreadNotFound rather than learning that it exists.
+func (s *ModerationService) CanRemoveThread(threadID, actorID uuid.UUID) error {
+	return s.canRemove(threadID, nil, actorID, ErrNotThreadOwner)
+}
+
+// CanRemoveComment is CanRemoveThread for a comment by authorID in the
+// thread.
+func (s *ModerationService) CanRemoveComment(threadID, authorID, actorID uuid.UUID) error {
+	return s.canRemove(threadID, &authorID, actorID, ErrNotCommentOwner)
+}
+
+// canRemove checks removal of content by authorID, or of the thread itself
+// when authorID is nil, and returns notAllowed when the actor may not.
+func (s *ModerationService) canRemove(threadID uuid.UUID, authorID *uuid.UUID, actorID uuid.UUID, notAllowed error) error {
+	thread, err := s.threads.GetForModeration(threadID, actorID)
+	if err != nil {
+		return ErrThreadNotFound
+	}
+	if authorID == nil {
+		authorID = &thread.UserID
+	}
+	if *authorID == actorID {
+		return nil
+	}
+	actor, err := s.users.GetByID(actorID)
+	if err != nil {
+		return err
+	}
+	canModerate, err := s.canModerate(actor, thread)
+	if err != nil || canModerate {
+		return err
+	}
+	if _, err := s.threads.GetThreadById(threadID, actorID); err != nil {
+		return ErrThreadNotFound
+	}
+	return notAllowed
+}
+
+// authorize loads the actor and the thread and fails with ErrNotModerator
+// unless the actor wrote the thread or may moderate it. Moderators reach
+// threads they could not see as readers; anyone else who cannot see the
+// thread gets ErrThreadNotFound.
+func (s *ModerationService) authorize(threadID, actorID uuid.UUID) (*model.User, *model.Thread, bool, error) {
+	actor, err := s.users.GetByID(actorID)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	thread, err := s.threads.GetForModeration(threadID, actorID)
+	if err != nil {
+		return nil, nil, false, ErrThreadNotFound
+	}
+	canModerate, err := s.canModerate(actor, thread)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if thread.UserID != actorID && !canModerate {
+		if _, err := s.threads.GetThreadById(threadID, actorID); err != nil {
+			return nil, nil, false, ErrThreadNotFound
+		}
+		return nil, nil, false, ErrNotModerator
+	}
+	return actor, thread, canModerate, nil
+}
+
+// canModerate reports whether actor is a site moderator or moderates the
+// community the thread was posted into.
+func (s *ModerationService) canModerate(actor *model.User, thread *model.Thread) (bool, error) {
+	if actor.IsModerator() {
+		return true, nil
+	}
+	if thread.CommunityID == nil {
+		return false, nil
+	}
+	community, err := s.communities.GetByID(*thread.CommunityID, actor.ID)
+	if err != nil {
+		return false, err
+	}
+	return community.CanModerate(), nil
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"github.com/google/uuid"
+)
+
+type NotificationService struct {
+	repo usecase.NotificationRepository
+}
+
+func NewNotificationService(repo usecase.NotificationRepository) *NotificationService {
+	return &NotificationService{repo: repo}
+}
+func (s *NotificationService) GetByUser(userID uuid.UUID, limit, offset int) ([]*model.Notification, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	return s.repo.GetByUser(userID, normalizePageSize(limit), offset)
+}
+func (s *NotificationService) CountUnread(userID uuid.UUID) (int, error) {
+	return s.repo.CountUnread(userID)
+}
+func (s *NotificationService) MarkRead(id, userID uuid.UUID) error {
+	return s.repo.MarkRead(id, userID)
+}
+func (s *NotificationService) MarkAllRead(userID uuid.UUID) error {
+	return s.repo.MarkAllRead(userID)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/google/uuid"
+)
+
+const MaxPinnedThreads = 3
+
+var (
+	ErrNotThreadOwner     = errors.New("you can only do this with your own threads")
+	ErrTooManyPins        = errors.New("you can pin at most 3 threads")
+	ErrAlreadyPinned      = errors.New("thread is already pinned")
+	ErrInvalidPinOrder    = errors.New("the order must list exactly your pinned threads")
+	ErrThreadNotPublished = errors.New("only published threads can be pinned")
+)
+
+type PinService struct {
+	repo       usecase.PinRepository
+	threadRepo usecase.ThreadRepository
+}
+
+func NewPinService(repo usecase.PinRepository, threadRepo usecase.ThreadRepository) *PinService {
+	return &PinService{repo: repo, threadRepo: threadRepo}
+}
+func (s *PinService) Pin(threadID, userID uuid.UUID) error {
+	thread, err := s.threadRepo.GetThreadById(threadID, userID)
+	if err != nil {
+		return ErrThreadNotFound
+	}
+	if thread.UserID != userID {
+		return ErrNotThreadOwner
+	}
+	if thread.Status != model.ThreadStatusPublished {
+		return ErrThreadNotPublished
+	}
+	pinned, err := s.repo.GetPinnedIDs(userID)
+	if err != nil {
+		return err
+	}
+	for _, id := range pinned {
+		if id == threadID {
+			return ErrAlreadyPinned
+		}
+	}
+	ok, err := s.repo.Pin(userID, threadID, MaxPinnedThreads)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTooManyPins
+	}
+	return nil
+}
+func (s *PinService) Unpin(threadID, userID uuid.UUID) error {
+	return s.repo.Unpin(userID, threadID)
+}
+func (s *PinService) Reorder(userID uuid.UUID, threadIDs []uuid.UUID) error {
+	pinned, err := s.repo.GetPinnedIDs(userID)
+	if err != nil {
+		return err
+	}
+	if len(pinned) != len(threadIDs) {
+		return ErrInvalidPinOrder
+	}
+	current := make(map[uuid.UUID]bool, len(pinned))
+	for _, id := range pinned {
+		current[id] = true
+	}
+	for _, id := range threadIDs {
+		if !current[id] {
+			return ErrInvalidPinOrder
+		}
+		delete(current, id)
+	}
+	return s.repo.Reorder(userID, threadIDs)
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"time"
+)
+
+const maxPollOptionLength = 100
+
+var (
+	ErrInvalidPoll     = errors.New("invalid poll")
+	ErrPollNotFound    = errors.New("poll not found")
+	ErrPollClosed      = errors.New("poll is closed")
+	ErrAlreadyVoted    = errors.New("you have already voted in this poll")
+	ErrInvalidPollVote = errors.New("invalid poll options selected")
+)
+
+type PollService struct {
+	repo usecase.PollRepository
+}
+
+func NewPollService(repo usecase.PollRepository) *PollService {
+	return &PollService{repo: repo}
+}
+func (s *PollService) Vote(threadID, userID uuid.UUID, optionIDs []uuid.UUID) (*model.Poll, error) {
+	return s.castVote(threadID, userID, optionIDs, false)
+}
+func (s *PollService) ChangeVote(threadID, userID uuid.UUID, optionIDs []uuid.UUID) (*model.Poll, error) {
+	return s.castVote(threadID, userID, optionIDs, true)
+}
+
+func (s *PollService) castVote(threadID, userID uuid.UUID, optionIDs []uuid.UUID, allowChange bool) (*model.Poll, error) {
+	poll, err := s.repo.GetByThread(threadID, userID)
+	if err != nil {
+		return nil, ErrPollNotFound
+	}
+	if poll.Closed {
+		return nil, ErrPollClosed
+	}
+	if len(poll.MyVotes) > 0 && !allowChange {
+		return nil, ErrAlreadyVoted
+	}
+	if len(optionIDs) == 0 || (!poll.Multiple && len(optionIDs) > 1) {
+		return nil, ErrInvalidPollVote
+	}
+	valid := make(map[uuid.UUID]bool, len(poll.Options))
+	for _, o := range poll.Options {
+		valid[o.ID] = true
+	}
+	seen := make(map[uuid.UUID]bool, len(optionIDs))
+	for _, id := range optionIDs {
+		if !valid[id] || seen[id] {
+			return nil, ErrInvalidPollVote
+		}
+		seen[id] = true
+	}
+	if err := s.repo.ReplaceVotes(poll.ID, userID, optionIDs); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByThread(threadID, userID)
+}
+
+// preparePoll validates a poll submitted with a new thread and assigns its IDs.
+func preparePoll(poll *model.Poll, now time.Time) error {
+	if len(poll.Options) < model.MinPollOptions || len(poll.Options) > model.MaxPollOptions {
+		return fmt.Errorf("%w: a poll needs %d to %d options", ErrInvalidPoll, model.MinPollOptions, model.MaxPollOptions)
+	}
+	if poll.ClosesAt != nil && !poll.ClosesAt.After(now) {
+		return fmt.Errorf("%w: closing time must be in the future", ErrInvalidPoll)
+	}
+	for i, o := range poll.Options {
+		o.Text = strings.TrimSpace(o.Text)
+		if o.Text == "" || len([]rune(o.Text)) > maxPollOptionLength {
+			return fmt.Errorf("%w: option text must be 1 to %d characters", ErrInvalidPoll, maxPollOptionLength)
+		}
+		o.ID = uuid.New()
+		o.Position = i
+		o.Votes = nil
+	}
+	poll.ID = uuid.New()
+	poll.MyVotes = []uuid.UUID{}
+	poll.ApplyResultVisibility(now)
+	return nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"github.com/google/uuid"
+)
+
+// RevisionService exposes the edit history of threads and comments to
+// anyone who can see the thread.
+type RevisionService struct {
+	repo     usecase.RevisionRepository
+	comments usecase.CommentRepository
+	access   *ThreadAccess
+}
+
+func NewRevisionService(repo usecase.RevisionRepository, comments usecase.CommentRepository, access *ThreadAccess) *RevisionService {
+	return &RevisionService{repo: repo, comments: comments, access: access}
+}
+
+func (s *RevisionService) GetThreadRevisions(threadID, viewerID uuid.UUID) ([]*model.Revision, error) {
+	if _, err := s.access.Visible(threadID, viewerID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByTarget(model.RevisionTargetThread, threadID)
+}
+
+func (s *RevisionService) GetCommentRevisions(commentID, viewerID uuid.UUID) ([]*model.Revision, error) {
+	comment, err := s.comments.GetByID(commentID)
+	if err != nil || comment.IsDeleted {
+		return nil, ErrCommentNotFound
+	}
+	if _, err := s.access.Visible(comment.ThreadID, viewerID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByTarget(model.RevisionTargetComment, commentID)
+}
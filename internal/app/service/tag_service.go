@@ -0,0 +1,44 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+type TagService struct {
+	repo       usecase.TagRepository
+	threadRepo usecase.ThreadRepository
+}
+
+func NewTagService(repo usecase.TagRepository, threadRepo usecase.ThreadRepository) *TagService {
+	return &TagService{repo: repo, threadRepo: threadRepo}
+}
+func (s *TagService) GetThreadsByTag(tag string, viewerID uuid.UUID, limit, offset int) ([]*model.Thread, error) {
+	if offset < 0 {
+		offset = 0
+	}
+	return s.threadRepo.GetByTag(NormalizeTag(tag), viewerID, normalizePageSize(limit), offset)
+}
+func (s *TagService) Autocomplete(prefix string, limit int) ([]*model.Tag, error) {
+	prefix = NormalizeTag(prefix)
+	if prefix == "" {
+		return []*model.Tag{}, nil
+	}
+	return s.repo.Autocomplete(prefix, normalizePageSize(limit))
+}
+
+func normalizePageSize(limit int) int {
+	if limit <= 0 {
+		return DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		return MaxPageSize
+	}
+	return limit
+}
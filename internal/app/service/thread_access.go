@@ -0,0 +1,72 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"errors"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrReplyNotAllowed = errors.New("the author has limited who can reply to this thread")
+	ErrThreadLocked    = errors.New("this thread is locked")
+)
+
+// ThreadAccess answers whether a user may see or reply to a thread. Thread
+// reads already filter by visibility, so a thread that cannot be loaded for
+// the viewer is treated as not found.
+type ThreadAccess struct {
+	threads usecase.ThreadRepository
+	follows usecase.FollowRepository
+}
+
+func NewThreadAccess(threads usecase.ThreadRepository, follows usecase.FollowRepository) *ThreadAccess {
+	return &ThreadAccess{threads: threads, follows: follows}
+}
+
+// Visible loads a published thread the viewer is allowed to see.
+func (a *ThreadAccess) Visible(threadID, viewerID uuid.UUID) (*model.Thread, error) {
+	thread, err := a.threads.GetThreadById(threadID, viewerID)
+	if err != nil || thread.Status != model.ThreadStatusPublished {
+		return nil, ErrThreadNotFound
+	}
+	return thread, nil
+}
+
+// CheckReply returns ErrThreadLocked when the thread is locked, and
+// ErrReplyNotAllowed when its reply policy excludes the user.
+func (a *ThreadAccess) CheckReply(thread *model.Thread, userID uuid.UUID) error {
+	if thread.Locked {
+		return ErrThreadLocked
+	}
+	if thread.UserID == userID {
+		return nil
+	}
+	switch thread.ReplyPolicy {
+	case model.ReplyEveryone, "":
+		return nil
+	case model.ReplyFollowers:
+		following, err := a.follows.IsFollowing(userID, thread.UserID)
+		if err != nil {
+			return err
+		}
+		if following {
+			return nil
+		}
+	case model.ReplyMentioned:
+		for _, m := range thread.Mentions {
+			if m.UserID == userID {
+				return nil
+			}
+		}
+	}
+	return ErrReplyNotAllowed
+}
+
+func validVisibility(v string) bool {
+	return v == model.VisibilityPublic || v == model.VisibilityFollowers || v == model.VisibilityMentioned
+}
+
+func validReplyPolicy(p string) bool {
+	return p == model.ReplyEveryone || p == model.ReplyFollowers || p == model.ReplyMentioned || p == model.ReplyNobody
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const expiredBatchSize = 100
+
+// ThreadReaper periodically deletes threads whose TTL has run out. Reads
+// already hide expired threads, so the reaper only has to catch up
+// eventually; like the scheduler it is safe to run on every replica.
+type ThreadReaper struct {
+	threads  *ThreadService
+	interval time.Duration
+}
+
+func NewThreadReaper(threads *ThreadService, interval time.Duration) *ThreadReaper {
+	return &ThreadReaper{threads: threads, interval: interval}
+}
+
+func (r *ThreadReaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		r.deleteExpired()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *ThreadReaper) deleteExpired() {
+	for {
+		n, err := r.threads.DeleteExpired(time.Now(), expiredBatchSize)
+		if err != nil {
+			log.Println("Failed to delete expired threads:", err)
+			return
+		}
+		if n < expiredBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+const scheduledBatchSize = 100
+
+// ThreadScheduler periodically publishes scheduled threads. It is safe to run
+// on every replica: each due thread is claimed by exactly one of them.
+type ThreadScheduler struct {
+	threads  *ThreadService
+	interval time.Duration
+}
+
+func NewThreadScheduler(threads *ThreadService, interval time.Duration) *ThreadScheduler {
+	return &ThreadScheduler{threads: threads, interval: interval}
+}
+
+func (s *ThreadScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+	for {
+		s.publishDue()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *ThreadScheduler) publishDue() {
+	for {
+		n, err := s.threads.PublishDue(time.Now(), scheduledBatchSize)
+		if err != nil {
+			log.Println("Failed to publish scheduled threads:", err)
+			return
+		}
+		if n < scheduledBatchSize {
+			return
+		}
+	}
+}
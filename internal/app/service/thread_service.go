@@ -3,34 +3,375 @@ package service
 import (
 	"WebMessanger/internal/model"
 	"WebMessanger/internal/usecase"
+	"database/sql"
+	"errors"
 	"github.com/google/uuid"
+	"strings"
 	"time"
+	"unicode/utf8"
+)
+
+var (
+	ErrThreadNotFound      = errors.New("thread not found")
+	ErrRepostOwnThread     = errors.New("you cannot repost your own thread")
+	ErrInvalidThreadStatus = errors.New("status must be published or draft")
+	ErrInvalidPublishTime  = errors.New("publish_at must be in the future")
+	ErrAlreadyPublished    = errors.New("thread is already published")
+	ErrInvalidVisibility   = errors.New("visibility must be public, followers or mentioned")
+	ErrInvalidReplyPolicy  = errors.New("reply_policy must be everyone, followers, mentioned or nobody")
+	ErrThreadNotPublic     = errors.New("only public threads can be reposted or quoted")
+	ErrInvalidTTL          = errors.New("ttl must be 1h, 24h or 7d")
 )
 
 type ThreadService struct {
-	repo usecase.ThreadRepository
+	repo        usecase.ThreadRepository
+	tagRepo     usecase.TagRepository
+	mentions    *MentionService
+	pollRepo    usecase.PollRepository
+	previews    *LinkPreviewWorker
+	attachments *AttachmentValidator
+	communities usecase.CommunityRepository
+	categories  usecase.CategoryRepository
+	moderation  *ModerationService
 }
 
-func NewThreadService(repo usecase.ThreadRepository) *ThreadService {
-	return &ThreadService{repo: repo}
+func NewThreadService(repo usecase.ThreadRepository, tagRepo usecase.TagRepository, mentions *MentionService, pollRepo usecase.PollRepository, previews *LinkPreviewWorker, attachments *AttachmentValidator, communities usecase.CommunityRepository, categories usecase.CategoryRepository, moderation *ModerationService) *ThreadService {
+	return &ThreadService{repo: repo, tagRepo: tagRepo, mentions: mentions, pollRepo: pollRepo, previews: previews, attachments: attachments, communities: communities, categories: categories, moderation: moderation}
 }
 func (s *ThreadService) Create(thread *model.Thread) (*model.Thread, error) {
+	if thread.QuoteOfID != nil {
+		quoted, err := s.repo.GetThreadById(*thread.QuoteOfID, thread.UserID)
+		if err != nil || quoted.Status != model.ThreadStatusPublished {
+			return nil, ErrThreadNotFound
+		}
+		if quoted.Visibility != model.VisibilityPublic {
+			return nil, ErrThreadNotPublic
+		}
+	}
+	if thread.CommunityID != nil {
+		community, err := s.communities.GetByID(*thread.CommunityID, thread.UserID)
+		if err != nil {
+			return nil, ErrCommunityNotFound
+		}
+		if community.MyRole == "" {
+			return nil, ErrNotCommunityMember
+		}
+	}
 	thread.ID = uuid.New()
 	thread.CreatedAt = time.Now()
-	return s.repo.Create(thread)
+	var v validator
+	setInitialStatus(&v, thread)
+	setExpiry(&v, thread)
+	setAudience(&v, thread)
+	checkAttachments(&v, s.attachments.Prepare(thread, nil))
+	validateThread(&v, thread)
+	s.checkCategory(&v, thread)
+	setSensitivity(&v, thread)
+	if thread.Poll != nil {
+		v.check("poll", CodeInvalidValue, preparePoll(thread.Poll, thread.CreatedAt))
+	}
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	thread.ContentHTML = RenderMarkdown(thread.Content)
+	thread.PreviewURL = FirstURL(thread.Content)
+	created, err := s.repo.Create(thread)
+	if err != nil {
+		return nil, err
+	}
+	if created.Poll != nil {
+		if err := s.pollRepo.Create(created.ID, created.Poll); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.indexContent(created); err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 func (s *ThreadService) Update(thread *model.Thread) (*model.Thread, error) {
-	return s.repo.Update(thread)
+	stored, err := s.repo.GetThreadById(thread.ID, thread.UserID)
+	if err != nil {
+		return nil, ErrThreadNotFound
+	}
+	var v validator
+	setAudience(&v, thread)
+	checkAttachments(&v, s.attachments.Prepare(thread, stored.Attachments))
+	validateThread(&v, thread)
+	s.checkCategory(&v, thread)
+	setSensitivity(&v, thread)
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	if thread.FlaggedByModerator && !thread.Sensitive {
+		return nil, ErrSensitiveLocked
+	}
+	thread.ContentHTML = RenderMarkdown(thread.Content)
+	previewURL := FirstURL(thread.Content)
+	if previewURL != thread.PreviewURL {
+		thread.Preview = nil
+	}
+	thread.PreviewURL = previewURL
+	updated, err := s.repo.Update(thread)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.indexContent(updated); err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
+
+// Delete soft-deletes a thread on behalf of its author or a moderator. Its
+// tags, attachments and comments are kept so the author can restore it
+// within model.RestoreWindow.
+func (s *ThreadService) Delete(id, actorID uuid.UUID) error {
+	if err := s.moderation.CanRemoveThread(id, actorID); err != nil {
+		return err
+	}
+	return s.repo.Delete(id, actorID)
+}
+
+// GetDeleted lists the threads the user deleted and can still restore.
+func (s *ThreadService) GetDeleted(userID uuid.UUID) ([]*model.Thread, error) {
+	return s.repo.GetDeletedByUser(userID, time.Now().Add(-model.RestoreWindow))
+}
+
+// Restore brings back a thread the user deleted themselves. Threads removed
+// by a moderator, or deleted longer ago than model.RestoreWindow, cannot be
+// restored.
+func (s *ThreadService) Restore(id, userID uuid.UUID) (*model.Thread, error) {
+	restored, err := s.repo.Restore(id, userID, time.Now().Add(-model.RestoreWindow))
+	if err != nil {
+		return nil, err
+	}
+	if !restored {
+		return nil, ErrThreadNotFound
+	}
+	return s.repo.GetThreadById(id, userID)
+}
+func (s *ThreadService) GetAllThreads(viewerID uuid.UUID) ([]*model.Thread, error) {
+	return s.repo.GetAllThreads(viewerID)
 }
-func (s *ThreadService) Delete(id uuid.UUID) error {
-	return s.repo.Delete(id)
+
+// GetThreadById returns ErrThreadNotFound both for a missing thread and for
+// one the viewer may not see.
+func (s *ThreadService) GetThreadById(id, viewerID uuid.UUID) (*model.Thread, error) {
+	thread, err := s.repo.GetThreadById(id, viewerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrThreadNotFound
+	}
+	return thread, err
+}
+func (s *ThreadService) GetByUser(userID, viewerID uuid.UUID) ([]*model.Thread, error) {
+	return s.repo.GetByUser(userID, viewerID)
+}
+func (s *ThreadService) Repost(threadID, userID uuid.UUID) error {
+	thread, err := s.repo.GetThreadById(threadID, userID)
+	if err != nil {
+		return ErrThreadNotFound
+	}
+	if thread.UserID == userID {
+		return ErrRepostOwnThread
+	}
+	if thread.Status != model.ThreadStatusPublished || thread.Visibility != model.VisibilityPublic {
+		return ErrThreadNotPublic
+	}
+	return s.repo.AddRepost(uuid.New(), threadID, userID, time.Now())
 }
-func (s *ThreadService) GetAllThreads() ([]*model.Thread, error) {
-	return s.repo.GetAllThreads()
+func (s *ThreadService) Unrepost(threadID, userID uuid.UUID) error {
+	return s.repo.RemoveRepost(threadID, userID)
 }
-func (s *ThreadService) GetThreadById(id uuid.UUID) (*model.Thread, error) {
-	return s.repo.GetThreadById(id)
+func (s *ThreadService) GetDrafts(userID uuid.UUID) ([]*model.Thread, error) {
+	return s.repo.GetDrafts(userID)
+}
+
+// Publish publishes a draft or scheduled thread right away, or reschedules it
+// when publishAt is given.
+func (s *ThreadService) Publish(id, userID uuid.UUID, publishAt *time.Time) (*model.Thread, error) {
+	thread, err := s.repo.GetThreadById(id, userID)
+	if err != nil || thread.UserID != userID {
+		return nil, ErrThreadNotFound
+	}
+	if thread.Status == model.ThreadStatusPublished {
+		return nil, ErrAlreadyPublished
+	}
+	now := time.Now()
+	if publishAt != nil {
+		if !publishAt.After(now) {
+			return nil, ErrInvalidPublishTime
+		}
+		if err := s.repo.SetStatus(id, model.ThreadStatusScheduled, publishAt, thread.CreatedAt); err != nil {
+			return nil, err
+		}
+		thread.Status = model.ThreadStatusScheduled
+		thread.PublishAt = publishAt
+		thread.ExpiresAt = nil
+		return thread, nil
+	}
+	if err := s.repo.SetStatus(id, model.ThreadStatusPublished, nil, now); err != nil {
+		return nil, err
+	}
+	thread.Status = model.ThreadStatusPublished
+	thread.PublishAt = nil
+	thread.CreatedAt = now
+	if ttl, ok := model.ThreadTTLs[thread.TTL]; ok {
+		expiresAt := now.Add(ttl)
+		thread.ExpiresAt = &expiresAt
+	}
+	if err := s.mentions.NotifyAll(model.MentionSourceThread, thread.ID, thread.ID, thread.UserID); err != nil {
+		return nil, err
+	}
+	return thread, nil
+}
+
+// PublishDue publishes every scheduled thread whose time has come and sends
+// the mention notifications that were held back while it was unpublished.
+func (s *ThreadService) PublishDue(now time.Time, batchSize int) (int, error) {
+	published, err := s.repo.PublishDue(now, batchSize)
+	if err != nil {
+		return 0, err
+	}
+	for _, t := range published {
+		if err := s.mentions.NotifyAll(model.MentionSourceThread, t.ID, t.ID, t.UserID); err != nil {
+			return len(published), err
+		}
+	}
+	return len(published), nil
+}
+
+// DeleteExpired deletes threads whose TTL ran out by now, batchSize at a
+// time, and reports how many it removed in this batch.
+func (s *ThreadService) DeleteExpired(now time.Time, batchSize int) (int, error) {
+	return s.repo.DeleteExpired(now, batchSize)
 }
-func (s *ThreadService) GetByUser(userID uuid.UUID) ([]*model.Thread, error) {
-	return s.repo.GetByUser(userID)
+
+// PurgeDeleted permanently removes threads whose restore window closed by
+// now, batchSize at a time, and reports how many it removed in this batch.
+func (s *ThreadService) PurgeDeleted(now time.Time, batchSize int) (int, error) {
+	return s.repo.PurgeDeleted(now.Add(-model.RestoreWindow), batchSize)
+}
+
+// RenderMissingHTML renders content_html for up to limit threads that were
+// stored before Markdown rendering existed and reports how many it handled.
+func (s *ThreadService) RenderMissingHTML(limit int) (int, error) {
+	threads, err := s.repo.GetUnrendered(limit)
+	if err != nil {
+		return 0, err
+	}
+	for _, t := range threads {
+		if err := s.repo.SetContentHTML(t.ID, RenderMarkdown(t.Content)); err != nil {
+			return 0, err
+		}
+	}
+	return len(threads), nil
+}
+
+// checkAttachments records an error from AttachmentValidator.Prepare as a
+// problem with the attachments field.
+func checkAttachments(v *validator, err error) {
+	code := CodeInvalidValue
+	if errors.Is(err, ErrAttachmentURL) {
+		code = CodeInvalidURL
+	}
+	v.check("attachments", code, err)
+}
+
+// checkCategory makes sure the thread's category, if any, exists and fills
+// in its name.
+func (s *ThreadService) checkCategory(v *validator, thread *model.Thread) {
+	if thread.CategoryID == nil {
+		thread.CategoryName = ""
+		return
+	}
+	category, err := s.categories.GetByID(*thread.CategoryID)
+	if err != nil {
+		v.check("category_id", CodeNotFound, ErrCategoryNotFound)
+		return
+	}
+	thread.CategoryName = category.Name
+}
+
+// setAudience fills in the default visibility and reply policy and rejects
+// unknown values.
+func setAudience(v *validator, thread *model.Thread) {
+	if thread.Visibility == "" {
+		thread.Visibility = model.VisibilityPublic
+	}
+	if thread.ReplyPolicy == "" {
+		thread.ReplyPolicy = model.ReplyEveryone
+	}
+	if !validVisibility(thread.Visibility) {
+		v.check("visibility", CodeInvalidValue, ErrInvalidVisibility)
+	}
+	if !validReplyPolicy(thread.ReplyPolicy) {
+		v.check("reply_policy", CodeInvalidValue, ErrInvalidReplyPolicy)
+	}
+}
+
+// setSensitivity trims and checks the content warning. A thread with a
+// content warning is always sensitive.
+func setSensitivity(v *validator, thread *model.Thread) {
+	thread.ContentWarning = strings.TrimSpace(thread.ContentWarning)
+	if utf8.RuneCountInString(thread.ContentWarning) > model.MaxContentWarningLength {
+		v.check("content_warning", CodeTooLong, ErrContentWarningTooLong)
+	}
+	if thread.ContentWarning != "" {
+		thread.Sensitive = true
+	}
+}
+
+// setExpiry checks the TTL and, for a published thread, sets when it
+// expires. Drafts and scheduled threads get expires_at once they go out.
+func setExpiry(v *validator, thread *model.Thread) {
+	thread.ExpiresAt = nil
+	if thread.TTL == "" {
+		return
+	}
+	ttl, ok := model.ThreadTTLs[thread.TTL]
+	if !ok {
+		v.check("ttl", CodeInvalidValue, ErrInvalidTTL)
+		return
+	}
+	if thread.Status == model.ThreadStatusPublished {
+		expiresAt := thread.CreatedAt.Add(ttl)
+		thread.ExpiresAt = &expiresAt
+	}
+}
+
+func setInitialStatus(v *validator, thread *model.Thread) {
+	if thread.PublishAt != nil {
+		if !thread.PublishAt.After(thread.CreatedAt) {
+			v.check("publish_at", CodeInvalidValue, ErrInvalidPublishTime)
+			return
+		}
+		thread.Status = model.ThreadStatusScheduled
+		return
+	}
+	switch thread.Status {
+	case "", model.ThreadStatusPublished:
+		thread.Status = model.ThreadStatusPublished
+	case model.ThreadStatusDraft:
+	default:
+		v.check("status", CodeInvalidValue, ErrInvalidThreadStatus)
+	}
+}
+
+// indexContent refreshes the hashtags and mentions derived from the content
+// and queues its link for unfurling.
+func (s *ThreadService) indexContent(thread *model.Thread) error {
+	if thread.PreviewURL != "" {
+		s.previews.Enqueue(thread.PreviewURL)
+	}
+	thread.Tags = ExtractHashtags(thread.Content)
+	if err := s.tagRepo.SetThreadTags(thread.ID, thread.Tags); err != nil {
+		return err
+	}
+	mentions, err := s.mentions.Index(model.MentionSourceThread, thread.ID, thread.ID, thread.UserID, thread.Content,
+		thread.Status == model.ThreadStatusPublished)
+	if err != nil {
+		return err
+	}
+	thread.Mentions = mentions
+	return nil
 }
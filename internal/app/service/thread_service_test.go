@@ -0,0 +1,54 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"WebMessanger/internal/usecase"
+	"database/sql"
+	"errors"
+	"github.com/google/uuid"
+	"testing"
+)
+
+// fakeThreadRepo answers GetThreadById with a fixed thread or error. Other
+// methods are not expected to be called.
+type fakeThreadRepo struct {
+	usecase.ThreadRepository
+	thread *model.Thread
+	err    error
+}
+
+func (r *fakeThreadRepo) GetThreadById(id, viewerID uuid.UUID) (*model.Thread, error) {
+	return r.thread, r.err
+}
+
+func TestGetThreadByIdNotFound(t *testing.T) {
+	dbErr := errors.New("connection refused")
+	tests := []struct {
+		name    string
+		repoErr error
+		wantErr error
+	}{
+		{"found", nil, nil},
+		{"missing or hidden", sql.ErrNoRows, ErrThreadNotFound},
+		{"database failure", dbErr, dbErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeThreadRepo{err: tt.repoErr}
+			if tt.repoErr == nil {
+				repo.thread = &model.Thread{ID: uuid.New()}
+			}
+			s := NewThreadService(repo, nil, nil, nil, nil, nil, nil, nil, nil)
+			thread, err := s.GetThreadById(uuid.New(), uuid.New())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got %v, want %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrThreadNotFound) && errors.Is(err, sql.ErrNoRows) {
+				t.Error("sql.ErrNoRows leaked through")
+			}
+			if (thread != nil) != (tt.wantErr == nil) {
+				t.Errorf("got thread %v", thread)
+			}
+		})
+	}
+}
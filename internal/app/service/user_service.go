@@ -18,6 +18,9 @@ func NewUserService(repo usecase.UserRepository) usecase.UserUsecase {
 }
 
 func (s *userService) Register(user *model.User) (*model.User, error) {
+	if err := ValidateRegistration(user); err != nil {
+		return nil, err
+	}
 	if existing, _ := s.repo.GetByName(user.Name); existing != nil {
 		return nil, errors.New("user already exists")
 	}
@@ -57,6 +60,13 @@ func (s *userService) GetUserByID(id uuid.UUID) (*model.User, error) {
 }
 
 func (s *userService) UpdateProfile(user *model.User) error {
+	previous, err := s.repo.GetByID(user.ID)
+	if err != nil {
+		return err
+	}
+	if err := ValidateProfile(user, previous); err != nil {
+		return err
+	}
 	existing, _ := s.repo.GetByName(user.Name)
 	if existing != nil && existing.ID != user.ID {
 		return errors.New("this name is already taken")
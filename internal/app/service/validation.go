@@ -0,0 +1,323 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Error codes of FieldError. Clients may rely on them, so they must not
+// change once released.
+const (
+	CodeRequired     = "required"
+	CodeTooShort     = "too_short"
+	CodeTooLong      = "too_long"
+	CodeInvalidChars = "invalid_characters"
+	CodeInvalidURL   = "invalid_url"
+	CodeInvalidEmail = "invalid_email"
+	CodeInvalidSlug  = "invalid_slug"
+	CodeInvalidValue = "invalid_value"
+	CodeNotFound     = "not_found"
+)
+
+// usernamePattern allows the characters a mention can refer to, so every
+// user can be mentioned by name.
+var usernamePattern = regexp.MustCompile(`^[\p{L}\p{N}_.\-]+$`)
+
+// FieldError describes one invalid field of a request.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when one or more fields are invalid. It lists
+// every problem found rather than stopping at the first one.
+type ValidationError struct {
+	Fields []FieldError `json:"fields"`
+	// errs holds the sentinel errors behind the fields, so errors.Is still
+	// matches them.
+	errs []error
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		msgs[i] = f.Field + ": " + f.Message
+	}
+	return "validation failed: " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.errs
+}
+
+type validator struct {
+	fields []FieldError
+	errs   []error
+}
+
+func (v *validator) add(field, code, message string) {
+	v.fields = append(v.fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+// check records err, if any, as a problem with field.
+func (v *validator) check(field, code string, err error) {
+	if err == nil {
+		return
+	}
+	v.add(field, code, err.Error())
+	v.errs = append(v.errs, err)
+}
+
+// length checks that value has between min and max characters; a min above
+// zero makes the field required.
+func (v *validator) length(field, value string, min, max int) {
+	n := utf8.RuneCountInString(value)
+	switch {
+	case n == 0 && min > 0:
+		v.add(field, CodeRequired, field+" is required")
+	case n < min:
+		v.add(field, CodeTooShort, field+" is too short")
+	case n > max:
+		v.add(field, CodeTooLong, field+" is too long")
+	}
+}
+
+// url checks that an optional value is an absolute http(s) URL or, when
+// allowPath is set, a path on this server such as an uploaded avatar.
+func (v *validator) url(field, value string, allowPath bool) {
+	if value == "" {
+		return
+	}
+	if len(value) > model.MaxURLLength {
+		v.add(field, CodeTooLong, field+" is too long")
+		return
+	}
+	if !validURL(value, allowPath) {
+		v.add(field, CodeInvalidURL, field+" must be an http or https URL")
+	}
+}
+
+// validURL reports whether value is an absolute http(s) URL or, when
+// allowPath is set, a path on this server.
+func validURL(value string, allowPath bool) bool {
+	u, err := url.Parse(value)
+	switch {
+	case err != nil:
+		return false
+	case (u.Scheme == "http" || u.Scheme == "https") && u.Host != "":
+		return true
+	case allowPath && u.Scheme == "" && u.Host == "" && strings.HasPrefix(value, "/") && !strings.HasPrefix(value, "//"):
+		return true
+	}
+	return false
+}
+
+func (v *validator) err() error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: v.fields, errs: v.errs}
+}
+
+// ValidateThread normalizes the title and content of a thread and checks
+// their length. Content may only be empty when the thread has attachments or
+// a poll.
+func ValidateThread(thread *model.Thread) error {
+	var v validator
+	validateThread(&v, thread)
+	return v.err()
+}
+
+func validateThread(v *validator, thread *model.Thread) {
+	thread.Title = normalizeLine(thread.Title)
+	thread.Content = NormalizeText(thread.Content)
+	v.length("title", thread.Title, 0, model.MaxThreadTitleLength)
+	min := 1
+	if len(thread.Attachments) > 0 || thread.MediaURL != "" || thread.Poll != nil {
+		min = 0
+	}
+	v.length("content", thread.Content, min, model.MaxThreadContentLength)
+}
+
+// ValidateComment normalizes the content of a comment and checks its length.
+func ValidateComment(comment *model.Comment) error {
+	comment.Content = NormalizeText(comment.Content)
+	var v validator
+	v.length("content", comment.Content, 1, model.MaxCommentContentLength)
+	return v.err()
+}
+
+// ValidateMessage normalizes the content of a direct message and checks its
+// length.
+func ValidateMessage(message *model.Message) error {
+	message.Content = NormalizeText(message.Content)
+	var v validator
+	v.length("content", message.Content, 1, model.MaxMessageLength)
+	return v.err()
+}
+
+// ValidateProfile normalizes and checks the editable profile fields. Only
+// fields that differ from previous are checked, so a value saved under older,
+// looser rules doesn't block unrelated edits. A nil previous checks them all.
+func ValidateProfile(user, previous *model.User) error {
+	var v validator
+	validateProfile(&v, user, previous)
+	return v.err()
+}
+
+// ValidateRegistration checks a new account: the profile fields plus email
+// and password. The password is checked but never altered.
+func ValidateRegistration(user *model.User) error {
+	var v validator
+	validateProfile(&v, user, nil)
+	user.Email = strings.TrimSpace(user.Email)
+	if user.Email == "" {
+		v.add("email", CodeRequired, "email is required")
+	} else if addr, err := mail.ParseAddress(user.Email); err != nil || addr.Address != user.Email {
+		v.add("email", CodeInvalidEmail, "email is not a valid address")
+	}
+	// bcrypt only looks at the first 72 bytes, so the upper limit is in
+	// bytes rather than characters.
+	switch {
+	case user.Password == "":
+		v.add("password", CodeRequired, "password is required")
+	case utf8.RuneCountInString(user.Password) < model.MinPasswordLength:
+		v.add("password", CodeTooShort, "password is too short")
+	case len(user.Password) > model.MaxPasswordLength:
+		v.add("password", CodeTooLong, "password is too long")
+	}
+	return v.err()
+}
+
+func validateProfile(v *validator, user, previous *model.User) {
+	normalizeProfile(user)
+	var old model.User
+	if previous != nil {
+		old = *previous
+		normalizeProfile(&old)
+	}
+	changed := func(value, oldValue string) bool {
+		return previous == nil || value != oldValue
+	}
+
+	if changed(user.Name, old.Name) {
+		v.length("name", user.Name, model.MinNameLength, model.MaxNameLength)
+		if user.Name != "" && !usernamePattern.MatchString(user.Name) {
+			v.add("name", CodeInvalidChars, "name may only contain letters, digits, '_', '.' and '-'")
+		}
+	}
+	if changed(user.Bio, old.Bio) {
+		v.length("bio", user.Bio, 0, model.MaxBioLength)
+	}
+	if changed(user.Location, old.Location) {
+		v.length("location", user.Location, 0, model.MaxLocationLength)
+	}
+	if changed(user.SocialLinks, old.SocialLinks) {
+		v.url("social_links", user.SocialLinks, false)
+	}
+	if changed(user.AvatarURL, old.AvatarURL) {
+		v.url("avatar_url", user.AvatarURL, true)
+	}
+}
+
+func normalizeProfile(user *model.User) {
+	user.Name = strings.TrimSpace(user.Name)
+	user.Bio = NormalizeText(user.Bio)
+	user.Location = normalizeLine(user.Location)
+	user.SocialLinks = strings.TrimSpace(user.SocialLinks)
+	user.AvatarURL = strings.TrimSpace(user.AvatarURL)
+}
+
+// NormalizeText cleans up multi-line user text: line endings become \n,
+// control characters other than tabs and newlines are dropped, trailing
+// spaces are removed from each line, runs of blank lines are collapsed to
+// one, and the result is trimmed.
+func NormalizeText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.Map(func(r rune) rune {
+		if r == '\r' {
+			return '\n'
+		}
+		if unicode.IsControl(r) && r != '\n' && r != '\t' {
+			return -1
+		}
+		return r
+	}, s)
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	blank := 0
+	for _, line := range lines {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+		if line == "" {
+			if blank++; blank > 1 {
+				continue
+			}
+		} else {
+			blank = 0
+		}
+		out = append(out, line)
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// normalizeLine collapses all whitespace in single-line text to single
+// spaces and drops control characters.
+func normalizeLine(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// communityNamePattern is usernamePattern plus spaces.
+var communityNamePattern = regexp.MustCompile(`^[\p{L}\p{N}_.\- ]+$`)
+
+// ValidateCommunity normalizes and checks a community's name and description.
+func ValidateCommunity(community *model.Community) error {
+	community.Name = normalizeLine(community.Name)
+	community.Description = NormalizeText(community.Description)
+	var v validator
+	v.length("name", community.Name, model.MinCommunityNameLength, model.MaxCommunityNameLength)
+	if community.Name != "" && !communityNamePattern.MatchString(community.Name) {
+		v.add("name", CodeInvalidChars, "name may only contain letters, digits, spaces, '_', '.' and '-'")
+	}
+	v.length("description", community.Description, 0, model.MaxCommunityDescriptionLength)
+	return v.err()
+}
+
+// ValidateCategory normalizes and checks a category. A missing slug is
+// derived from the name.
+func ValidateCategory(category *model.Category) error {
+	category.Name = normalizeLine(category.Name)
+	category.Description = NormalizeText(category.Description)
+	category.Slug = strings.TrimSpace(category.Slug)
+	if category.Slug == "" {
+		category.Slug = Slugify(category.Name)
+	}
+	var v validator
+	v.length("name", category.Name, model.MinCategoryNameLength, model.MaxCategoryNameLength)
+	v.length("description", category.Description, 0, model.MaxCategoryDescriptionLength)
+	if category.Slug == "" && category.Name != "" {
+		v.add("slug", CodeRequired, "slug is required")
+	} else if category.Slug != Slugify(category.Slug) {
+		v.add("slug", CodeInvalidSlug, "slug may only contain lowercase letters, digits and single dashes")
+	}
+	return v.err()
+}
+
+// Slugify lowercases s and joins its runs of letters and digits with dashes.
+func Slugify(s string) string {
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return strings.Join(words, "-")
+}
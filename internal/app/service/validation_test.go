@@ -0,0 +1,217 @@
+package service
+
+import (
+	"WebMessanger/internal/model"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fieldCodes maps each invalid field of err to its code.
+func fieldCodes(t *testing.T, err error) map[string]string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("got %T %v, want *ValidationError", err, err)
+	}
+	codes := make(map[string]string)
+	for _, f := range verr.Fields {
+		codes[f.Field] = f.Code
+	}
+	return codes
+}
+
+func TestValidateThread(t *testing.T) {
+	tests := []struct {
+		name   string
+		thread model.Thread
+		want   map[string]string
+	}{
+		{"valid", model.Thread{Title: "Hello", Content: "world"}, nil},
+		{"title optional", model.Thread{Content: "world"}, nil},
+		{"content required", model.Thread{Title: "Hello", Content: " \n "}, map[string]string{"content": CodeRequired}},
+		{"content optional with poll", model.Thread{Poll: &model.Poll{}}, nil},
+		{"content optional with attachments", model.Thread{Attachments: []*model.Attachment{{URL: "/uploads/a.png"}}}, nil},
+		{"title too long", model.Thread{Title: strings.Repeat("a", model.MaxThreadTitleLength+1), Content: "x"}, map[string]string{"title": CodeTooLong}},
+		{"content too long", model.Thread{Content: strings.Repeat("ж", model.MaxThreadContentLength+1)}, map[string]string{"content": CodeTooLong}},
+		{"content at limit", model.Thread{Content: strings.Repeat("ж", model.MaxThreadContentLength)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fieldCodes(t, ValidateThread(&tt.thread))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateThreadNormalizes(t *testing.T) {
+	thread := model.Thread{Title: "  a \t title\x07 ", Content: "line  \r\n\r\n\r\nnext\x00"}
+	if err := ValidateThread(&thread); err != nil {
+		t.Fatal(err)
+	}
+	if thread.Title != "a title" {
+		t.Errorf("title = %q", thread.Title)
+	}
+	if thread.Content != "line\n\nnext" {
+		t.Errorf("content = %q", thread.Content)
+	}
+}
+
+func TestValidateComment(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+	}{
+		{"valid", "nice thread", nil},
+		{"required", "\t\n", map[string]string{"content": CodeRequired}},
+		{"too long", strings.Repeat("a", model.MaxCommentContentLength+1), map[string]string{"content": CodeTooLong}},
+		{"at limit", strings.Repeat("a", model.MaxCommentContentLength), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fieldCodes(t, ValidateComment(&model.Comment{Content: tt.content}))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		user     model.User
+		previous *model.User
+		want     map[string]string
+	}{
+		{"valid", model.User{Name: "alice", Bio: "hi", SocialLinks: "https://example.com", AvatarURL: "/uploads/a.png"}, nil, nil},
+		{"name required", model.User{Name: "  "}, nil, map[string]string{"name": CodeRequired}},
+		{"name too short", model.User{Name: "al"}, nil, map[string]string{"name": CodeTooShort}},
+		{"name too long", model.User{Name: strings.Repeat("a", model.MaxNameLength+1)}, nil, map[string]string{"name": CodeTooLong}},
+		{"name invalid characters", model.User{Name: "al ice"}, nil, map[string]string{"name": CodeInvalidChars}},
+		{"bio too long", model.User{Name: "alice", Bio: strings.Repeat("a", model.MaxBioLength+1)}, nil, map[string]string{"bio": CodeTooLong}},
+		{"location too long", model.User{Name: "alice", Location: strings.Repeat("a", model.MaxLocationLength+1)}, nil, map[string]string{"location": CodeTooLong}},
+		{"social link not http", model.User{Name: "alice", SocialLinks: "javascript:alert(1)"}, nil, map[string]string{"social_links": CodeInvalidURL}},
+		{"social link path", model.User{Name: "alice", SocialLinks: "/me"}, nil, map[string]string{"social_links": CodeInvalidURL}},
+		{"avatar protocol-relative", model.User{Name: "alice", AvatarURL: "//evil.example/a.png"}, nil, map[string]string{"avatar_url": CodeInvalidURL}},
+		{"avatar too long", model.User{Name: "alice", AvatarURL: "https://example.com/" + strings.Repeat("a", model.MaxURLLength)}, nil, map[string]string{"avatar_url": CodeTooLong}},
+		{
+			"unchanged legacy values are kept",
+			model.User{Name: "a b", Bio: strings.Repeat("a", model.MaxBioLength+1), AvatarURL: "/uploads/new.png"},
+			&model.User{Name: "a b", Bio: strings.Repeat("a", model.MaxBioLength+1), AvatarURL: "/uploads/old.png"},
+			nil,
+		},
+		{
+			"changed values are checked",
+			model.User{Name: "a c", Bio: "ok"},
+			&model.User{Name: "a b", Bio: "ok"},
+			map[string]string{"name": CodeInvalidChars},
+		},
+		{
+			"normalization alone is not a change",
+			model.User{Name: " a b ", Location: "far   away"},
+			&model.User{Name: "a b", Location: "far away"},
+			nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fieldCodes(t, ValidateProfile(&tt.user, tt.previous))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateRegistration(t *testing.T) {
+	valid := func(edit func(u *model.User)) model.User {
+		u := model.User{Name: "alice", Email: "alice@example.com", Password: "secret"}
+		if edit != nil {
+			edit(&u)
+		}
+		return u
+	}
+	tests := []struct {
+		name string
+		user model.User
+		want map[string]string
+	}{
+		{"valid", valid(nil), nil},
+		{"email trimmed", valid(func(u *model.User) { u.Email = " alice@example.com " }), nil},
+		{"email required", valid(func(u *model.User) { u.Email = "" }), map[string]string{"email": CodeRequired}},
+		{"email invalid", valid(func(u *model.User) { u.Email = "alice" }), map[string]string{"email": CodeInvalidEmail}},
+		{"email with display name", valid(func(u *model.User) { u.Email = "Alice <alice@example.com>" }), map[string]string{"email": CodeInvalidEmail}},
+		{"password required", valid(func(u *model.User) { u.Password = "" }), map[string]string{"password": CodeRequired}},
+		{"password too short", valid(func(u *model.User) { u.Password = "abcd" }), map[string]string{"password": CodeTooShort}},
+		{"password at byte limit", valid(func(u *model.User) { u.Password = strings.Repeat("a", model.MaxPasswordLength) }), nil},
+		{"password too long", valid(func(u *model.User) { u.Password = strings.Repeat("a", model.MaxPasswordLength+1) }), map[string]string{"password": CodeTooLong}},
+		// 37 two-byte characters are under 72 characters but over 72 bytes.
+		{"password too long in bytes", valid(func(u *model.User) { u.Password = strings.Repeat("ж", 37) }), map[string]string{"password": CodeTooLong}},
+		{"profile fields checked", valid(func(u *model.User) { u.Name = "al" }), map[string]string{"name": CodeTooShort}},
+		{
+			"every problem reported",
+			model.User{Name: "al", Email: "x", Password: "1"},
+			map[string]string{"name": CodeTooShort, "email": CodeInvalidEmail, "password": CodeTooShort},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fieldCodes(t, ValidateRegistration(&tt.user))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidationErrorUnwrap(t *testing.T) {
+	var v validator
+	v.check("visibility", CodeInvalidValue, ErrInvalidVisibility)
+	v.check("ttl", CodeInvalidValue, nil)
+	err := v.err()
+	if !errors.Is(err, ErrInvalidVisibility) {
+		t.Errorf("errors.Is(%v, ErrInvalidVisibility) = false", err)
+	}
+	if errors.Is(err, ErrInvalidTTL) {
+		t.Errorf("errors.Is(%v, ErrInvalidTTL) = true", err)
+	}
+	want := map[string]string{"visibility": CodeInvalidValue}
+	if got := fieldCodes(t, err); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", ""},
+		{"plain", "hello", "hello"},
+		{"trims", "  hello  ", "hello"},
+		{"crlf", "a\r\nb", "a\nb"},
+		{"lone cr", "a\rb", "a\nb"},
+		{"drops control characters", "a\x00b\x1bc\x7f", "abc"},
+		{"keeps tabs", "a\tb", "a\tb"},
+		{"trailing spaces per line", "a  \nb\t\n", "a\nb"},
+		{"collapses blank lines", "a\n\n\n\nb", "a\n\nb"},
+		{"whitespace-only lines are blank", "a\n  \n\t\n \nb", "a\n\nb"},
+		{"keeps indentation", "a\n  b", "a\n  b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeText(tt.in); got != tt.want {
+				t.Errorf("NormalizeText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
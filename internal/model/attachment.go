@@ -0,0 +1,24 @@
+package model
+
+import "github.com/google/uuid"
+
+const (
+	AttachmentImage = "image"
+	AttachmentVideo = "video"
+
+	MaxImageAttachments = 4
+	MaxVideoAttachments = 1
+	MaxAltTextLength    = 1000
+)
+
+// Attachment is one media item of a thread, shown in Position order.
+type Attachment struct {
+	ID       uuid.UUID         `json:"id"`
+	Position int               `json:"position"`
+	Type     string            `json:"type"`
+	URL      string            `json:"url"`
+	Width    *int              `json:"width,omitempty"`
+	Height   *int              `json:"height,omitempty"`
+	AltText  string            `json:"alt_text"`
+	Variants map[string]string `json:"variants,omitempty"`
+}
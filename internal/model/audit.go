@@ -0,0 +1,25 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const AuditTargetThread = "thread"
+
+const (
+	AuditThreadLock   = "thread.lock"
+	AuditThreadUnlock = "thread.unlock"
+)
+
+// AuditEntry records a moderation action. ActorID is nil once the acting
+// user's account is gone.
+type AuditEntry struct {
+	ID         uuid.UUID  `json:"id"`
+	ActorID    *uuid.UUID `json:"actor_id"`
+	ActorName  string     `json:"actor_name"`
+	Action     string     `json:"action"`
+	TargetType string     `json:"target_type"`
+	TargetID   uuid.UUID  `json:"target_id"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+type Bookmark struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	ThreadID  uuid.UUID `json:"thread_id"`
+	Folder    string    `json:"folder"`
+	CreatedAt time.Time `json:"created_at"`
+	Thread    *Thread   `json:"thread,omitempty"`
+}
+
+// BookmarkCursor points just past the last bookmark of a page.
+type BookmarkCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+type BookmarkFolder struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	MinCategoryNameLength        = 2
+	MaxCategoryNameLength        = 40
+	MaxCategoryDescriptionLength = 300
+)
+
+// Category is one entry of the admin-managed taxonomy threads can be filed
+// under. Categories are listed by Position, then by name.
+type Category struct {
+	ID          uuid.UUID `json:"id"`
+	Slug        string    `json:"slug"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Position    int       `json:"position"`
+	// ThreadCount counts the published threads in the category.
+	ThreadCount int       `json:"thread_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
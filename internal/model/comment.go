@@ -5,11 +5,28 @@ import (
 	"time"
 )
 
+const DeletedCommentContent = "[deleted]"
+
+const MaxCommentContentLength = 500
+
 type Comment struct {
-	ID        uuid.UUID `json:"id"`
-	ThreadID  uuid.UUID `json:"thread_id"`
-	UserID    uuid.UUID `json:"user_id"`
-	UserName  string    `json:"user_name"`
-	Content   string    `json:"content"`
-	CreatedAt time.Time `json:"created_at"`
+	ID             uuid.UUID  `json:"id"`
+	ThreadID       uuid.UUID  `json:"thread_id"`
+	ParentID       *uuid.UUID `json:"parent_id,omitempty"`
+	UserID         uuid.UUID  `json:"user_id"`
+	UserName       string     `json:"user_name"`
+	Content        string     `json:"content"`
+	ContentHTML    string     `json:"content_html"`
+	Mentions       []*Mention `json:"mentions"`
+	IsDeleted      bool       `json:"is_deleted"`
+	LikeCount      int        `json:"like_count"`
+	LikedByMe      bool       `json:"liked_by_me"`
+	MyReaction     string     `json:"my_reaction,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	EditedAt       *time.Time `json:"edited_at,omitempty"`
+	DeletedAt      *time.Time `json:"deleted_at,omitempty"`
+	Depth          int        `json:"depth"`
+	ReplyCount     int        `json:"reply_count"`
+	HasMoreReplies bool       `json:"has_more_replies"`
+	Replies        []*Comment `json:"replies,omitempty"`
 }
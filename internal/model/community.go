@@ -0,0 +1,45 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	CommunityRoleOwner     = "owner"
+	CommunityRoleModerator = "moderator"
+	CommunityRoleMember    = "member"
+)
+
+const (
+	MinCommunityNameLength        = 3
+	MaxCommunityNameLength        = 32
+	MaxCommunityDescriptionLength = 500
+)
+
+type Community struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	OwnerID     uuid.UUID `json:"owner_id"`
+	OwnerName   string    `json:"owner_name"`
+	MemberCount int       `json:"member_count"`
+	// MyRole is the viewer's role in the community, empty when they are not
+	// a member.
+	MyRole    string    `json:"my_role,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CanModerate reports whether the viewer may moderate the community's
+// threads and members.
+func (c *Community) CanModerate() bool {
+	return c.MyRole == CommunityRoleOwner || c.MyRole == CommunityRoleModerator
+}
+
+type CommunityMember struct {
+	UserID    uuid.UUID `json:"user_id"`
+	UserName  string    `json:"user_name"`
+	AvatarURL string    `json:"avatar_url"`
+	Role      string    `json:"role"`
+	JoinedAt  time.Time `json:"joined_at"`
+}
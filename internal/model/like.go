@@ -5,10 +5,29 @@ import (
 	"time"
 )
 
+const (
+	LikeTargetThread  = "thread"
+	LikeTargetComment = "comment"
+)
+
+// DefaultReaction is the reaction recorded by the plain like endpoints.
+const DefaultReaction = "👍"
+
 type Like struct {
-	ID        uuid.UUID
-	ThreadID  uuid.UUID
-	UserID    uuid.UUID
-	UserName  string
-	CreatedAt time.Time
+	ID         uuid.UUID
+	TargetType string
+	TargetID   uuid.UUID
+	ThreadID   uuid.UUID
+	UserID     uuid.UUID
+	UserName   string
+	Reaction   string
+	CreatedAt  time.Time
+}
+
+type ReactionSummary struct {
+	TargetType string         `json:"target_type"`
+	TargetID   uuid.UUID      `json:"target_id"`
+	Counts     map[string]int `json:"counts"`
+	Total      int            `json:"total"`
+	MyReaction string         `json:"my_reaction,omitempty"`
 }
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// LinkPreview is the OpenGraph/Twitter-card metadata of the first link in a
+// thread.
+type LinkPreview struct {
+	URL         string    `json:"url"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	ImageURL    string    `json:"image_url"`
+	SiteName    string    `json:"site_name"`
+	Failed      bool      `json:"-"`
+	FetchedAt   time.Time `json:"fetched_at"`
+}
@@ -0,0 +1,27 @@
+package model
+
+import "github.com/google/uuid"
+
+const (
+	MediaKindThread = "thread"
+	MediaKindAvatar = "avatar"
+)
+
+// Media describes an uploaded file. URL can be passed as-is to the thread
+// media_url and profile avatar_url fields.
+type Media struct {
+	URL         string `json:"url"`
+	Kind        string `json:"kind"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	Width       *int   `json:"width,omitempty"`
+	Height      *int   `json:"height,omitempty"`
+	// Variants maps variant names (avatar_64, avatar_256, feed, full) to
+	// their URLs. Videos have none.
+	Variants map[string]string `json:"variants,omitempty"`
+	// Keys are the storage keys of the stored files, known only for a fresh
+	// upload so it can be discarded.
+	Keys []string `json:"-"`
+	// UserID is the uploader, known when the upload is read back.
+	UserID uuid.UUID `json:"-"`
+}
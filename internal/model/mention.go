@@ -0,0 +1,18 @@
+package model
+
+import "github.com/google/uuid"
+
+const (
+	MentionSourceThread  = "thread"
+	MentionSourceComment = "comment"
+)
+
+// Mention marks an @username inside thread or comment content. Offset and
+// Length are measured in UTF-16 code units so clients can slice the content
+// directly in JavaScript.
+type Mention struct {
+	UserID   uuid.UUID `json:"user_id"`
+	UserName string    `json:"user_name"`
+	Offset   int       `json:"offset"`
+	Length   int       `json:"length"`
+}
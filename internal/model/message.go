@@ -0,0 +1,37 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const MaxMessageLength = 2000
+
+// Conversation is a direct-message thread between the viewer and one other
+// user.
+type Conversation struct {
+	ID          uuid.UUID   `json:"id"`
+	With        *PublicUser `json:"with"`
+	LastMessage *Message    `json:"last_message,omitempty"`
+	UnreadCount int         `json:"unread_count"`
+	// Blocked is set when either user blocked the other; no new messages
+	// can be sent until the block is lifted.
+	Blocked   bool      `json:"blocked"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Message struct {
+	ID             uuid.UUID `json:"id"`
+	ConversationID uuid.UUID `json:"conversation_id"`
+	SenderID       uuid.UUID `json:"sender_id"`
+	Content        string    `json:"content"`
+	CreatedAt      time.Time `json:"created_at"`
+	// Seq orders the messages of a conversation. It is assigned by the
+	// database, unlike CreatedAt, so it is unaffected by clock skew.
+	Seq int64 `json:"-"`
+}
+
+// MessageCursor points just past the last message of a page.
+type MessageCursor struct {
+	Seq int64
+}
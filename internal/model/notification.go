@@ -0,0 +1,20 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const NotificationMention = "mention"
+
+type Notification struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	ActorID   uuid.UUID  `json:"actor_id"`
+	ActorName string     `json:"actor_name"`
+	Type      string     `json:"type"`
+	ThreadID  *uuid.UUID `json:"thread_id,omitempty"`
+	CommentID *uuid.UUID `json:"comment_id,omitempty"`
+	IsRead    bool       `json:"is_read"`
+	CreatedAt time.Time  `json:"created_at"`
+}
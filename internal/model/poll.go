@@ -0,0 +1,43 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	MinPollOptions = 2
+	MaxPollOptions = 6
+)
+
+type Poll struct {
+	ID             uuid.UUID     `json:"id"`
+	Multiple       bool          `json:"multiple"`
+	ClosesAt       *time.Time    `json:"closes_at,omitempty"`
+	Closed         bool          `json:"closed"`
+	Options        []*PollOption `json:"options"`
+	TotalVoters    *int          `json:"total_voters,omitempty"`
+	MyVotes        []uuid.UUID   `json:"my_votes"`
+	ResultsVisible bool          `json:"results_visible"`
+}
+
+type PollOption struct {
+	ID       uuid.UUID `json:"id"`
+	Position int       `json:"position"`
+	Text     string    `json:"text"`
+	Votes    *int      `json:"votes,omitempty"`
+}
+
+// ApplyResultVisibility hides vote counts until the viewer has voted or the
+// poll has closed.
+func (p *Poll) ApplyResultVisibility(now time.Time) {
+	p.Closed = p.ClosesAt != nil && !now.Before(*p.ClosesAt)
+	p.ResultsVisible = p.Closed || len(p.MyVotes) > 0
+	if p.ResultsVisible {
+		return
+	}
+	p.TotalVoters = nil
+	for _, o := range p.Options {
+		o.Votes = nil
+	}
+}
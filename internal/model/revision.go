@@ -0,0 +1,23 @@
+package model
+
+import (
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	RevisionTargetThread  = "thread"
+	RevisionTargetComment = "comment"
+)
+
+// Revision is an earlier version of a thread or comment. CreatedAt is when
+// that version was written, i.e. when the item was created or last edited
+// before this version was replaced.
+type Revision struct {
+	ID         uuid.UUID `json:"id"`
+	TargetType string    `json:"target_type"`
+	TargetID   uuid.UUID `json:"target_id"`
+	Title      string    `json:"title,omitempty"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"created_at"`
+}
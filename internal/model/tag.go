@@ -0,0 +1,6 @@
+package model
+
+type Tag struct {
+	Name        string `json:"name"`
+	ThreadCount int    `json:"thread_count"`
+}
@@ -5,12 +5,114 @@ import (
 	"time"
 )
 
+const (
+	ThreadStatusPublished = "published"
+	ThreadStatusDraft     = "draft"
+	ThreadStatusScheduled = "scheduled"
+)
+
+const (
+	VisibilityPublic    = "public"
+	VisibilityFollowers = "followers"
+	VisibilityMentioned = "mentioned"
+)
+
+const (
+	MaxThreadTitleLength    = 120
+	MaxThreadContentLength  = 500
+	MaxContentWarningLength = 100
+)
+
+// ThreadTTLs are the lifetimes a story-style thread can be given; it is
+// deleted that long after it is published.
+var ThreadTTLs = map[string]time.Duration{
+	"1h":  time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+}
+
+// RestoreWindow is how long the author can restore a deleted thread or
+// comment before it is purged for good.
+const RestoreWindow = 30 * 24 * time.Hour
+
+const (
+	ReplyEveryone  = "everyone"
+	ReplyFollowers = "followers"
+	ReplyMentioned = "mentioned"
+	ReplyNobody    = "nobody"
+)
+
 type Thread struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    uuid.UUID `json:"user_id"`
-	UserName  string    `json:"user_name"`
-	AvatarURL string    `json:"avatar_url"`
-	Content   string    `json:"content"`   // текст поста
-	MediaURL  string    `json:"media_url"` // (опционально) фото/видео
-	CreatedAt time.Time `json:"created_at"`
+	ID             uuid.UUID         `json:"id"`
+	UserID         uuid.UUID         `json:"user_id"`
+	UserName       string            `json:"user_name"`
+	AvatarURL      string            `json:"avatar_url"`
+	AvatarVariants map[string]string `json:"avatar_variants"`
+	Title          string            `json:"title"`
+	Content        string            `json:"content"` // текст поста
+	ContentHTML    string            `json:"content_html"`
+	MediaURL       string            `json:"media_url"` // первое вложение, для старых клиентов
+	MediaVariants  map[string]string `json:"media_variants"`
+	Attachments    []*Attachment     `json:"attachments"`
+	CommunityID    *uuid.UUID        `json:"community_id,omitempty"`
+	CommunityName  string            `json:"community_name,omitempty"`
+	CategoryID     *uuid.UUID        `json:"category_id,omitempty"`
+	CategoryName   string            `json:"category_name,omitempty"`
+	Tags           []string          `json:"tags"`
+	Mentions       []*Mention        `json:"mentions"`
+	QuoteOfID      *uuid.UUID        `json:"quote_of_id,omitempty"`
+	Quoted         *QuotedThread     `json:"quoted,omitempty"`
+	RepostCount    int               `json:"repost_count"`
+	QuoteCount     int               `json:"quote_count"`
+	RepostedBy     *Repost           `json:"reposted_by,omitempty"`
+	BookmarkedByMe bool              `json:"bookmarked_by_me"`
+	Poll           *Poll             `json:"poll,omitempty"`
+	PreviewURL     string            `json:"-"`
+	Preview        *LinkPreview      `json:"preview,omitempty"`
+	Status         string            `json:"status"`
+	PublishAt      *time.Time        `json:"publish_at,omitempty"`
+	Pinned         bool              `json:"pinned"`
+	Visibility     string            `json:"visibility"`
+	Sensitive      bool              `json:"sensitive"`
+	ContentWarning string            `json:"content_warning"`
+	// FlaggedByModerator is set when a moderator marked the thread sensitive;
+	// the author can then no longer clear the flag.
+	FlaggedByModerator bool `json:"flagged_by_moderator"`
+	// Blurred tells clients to blur the media of a sensitive thread, per the
+	// viewer's preference.
+	Blurred     bool   `json:"blurred"`
+	ReplyPolicy string `json:"reply_policy"`
+	// Locked threads accept no new comments or likes.
+	Locked   bool       `json:"locked"`
+	LockedBy *uuid.UUID `json:"locked_by,omitempty"`
+	LockedAt *time.Time `json:"locked_at,omitempty"`
+	// TTL is one of ThreadTTLs, or empty for a thread that never expires.
+	TTL       string     `json:"ttl,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// QuotedThread is the embedded preview of the thread a quote refers to. When
+// the original has been deleted only ID and Unavailable are set, and Content
+// holds UnavailableThreadContent.
+type QuotedThread struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id,omitempty"`
+	UserName    string    `json:"user_name,omitempty"`
+	AvatarURL   string    `json:"avatar_url,omitempty"`
+	Content     string    `json:"content,omitempty"`
+	ContentHTML string    `json:"content_html,omitempty"`
+	MediaURL    string    `json:"media_url,omitempty"`
+	Unavailable bool      `json:"unavailable"`
+}
+
+// Repost attributes a feed entry to the user who reposted it.
+type Repost struct {
+	UserID     uuid.UUID `json:"user_id"`
+	UserName   string    `json:"user_name"`
+	RepostedAt time.Time `json:"reposted_at"`
 }
+
+const UnavailableThreadContent = "post unavailable"
@@ -6,6 +6,31 @@ import (
 	"time"
 )
 
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Values of User.SensitiveMedia, the viewer's preference for threads marked
+// as sensitive.
+const (
+	SensitiveMediaShow = "show"
+	SensitiveMediaBlur = "blur"
+	SensitiveMediaHide = "hide"
+)
+
+// Profile limits, counted in characters.
+const (
+	MinNameLength     = 3
+	MaxNameLength     = 16
+	MinPasswordLength = 5
+	MaxPasswordLength = 72
+	MaxBioLength      = 160
+	MaxLocationLength = 64
+	MaxURLLength      = 2048
+)
+
 type User struct {
 	ID             uuid.UUID
 	Name           string
@@ -16,8 +41,22 @@ type User struct {
 	Location       string
 	SocialLinks    string
 	AvatarURL      string
+	AvatarVariants map[string]string
 	CreatedAt      time.Time
 	IsVerified     bool
+	SensitiveMedia string
+	Role           string
+}
+
+// IsAdmin reports whether the user may manage site-wide settings such as
+// categories.
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// IsModerator reports whether the user may moderate other people's content.
+func (u *User) IsModerator() bool {
+	return u.Role == RoleModerator || u.Role == RoleAdmin
 }
 
 type Claims struct {
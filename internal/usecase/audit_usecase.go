@@ -0,0 +1,10 @@
+package usecase
+
+import (
+	"WebMessanger/internal/model"
+	"github.com/google/uuid"
+)
+
+type AuditRepository interface {
+	GetByTarget(targetType string, targetID uuid.UUID) ([]*model.AuditEntry, error)
+}
@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"WebMessanger/internal/model"
+	"github.com/google/uuid"
+)
+
+type BlockUsecase interface {
+	Block(blockerID, blockedID uuid.UUID) error
+	Unblock(blockerID, blockedID uuid.UUID) error
+	GetBlocked(userID uuid.UUID) ([]*model.PublicUser, error)
+}
+
+type BlockRepository interface {
+	Block(blockerID, blockedID uuid.UUID) error
+	Unblock(blockerID, blockedID uuid.UUID) error
+	// IsBlocked reports whether either user has blocked the other.
+	IsBlocked(userID, otherID uuid.UUID) (bool, error)
+	GetBlocked(userID uuid.UUID) ([]*model.PublicUser, error)
+}
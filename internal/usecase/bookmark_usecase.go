@@ -0,0 +1,20 @@
+package usecase
+
+import (
+	"WebMessanger/internal/model"
+	"github.com/google/uuid"
+)
+
+type BookmarkUsecase interface {
+	Add(bookmark *model.Bookmark) (*model.Bookmark, error)
+	Remove(threadID, userID uuid.UUID) error
+	List(userID uuid.UUID, folder *string, cursor string, limit int) ([]*model.Bookmark, string, error)
+	GetFolders(userID uuid.UUID) ([]*model.BookmarkFolder, error)
+}
+
+type BookmarkRepository interface {
+	Add(bookmark *model.Bookmark) error
+	Remove(threadID, userID uuid.UUID) error
+	List(userID uuid.UUID, folder *string, after *model.BookmarkCursor, limit int) ([]*model.Bookmark, error)
+	GetFolders(userID uuid.UUID) ([]*model.BookmarkFolder, error)
+}
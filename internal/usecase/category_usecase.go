@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"WebMessanger/internal/model"
+	"github.com/google/uuid"
+)
+
+type CategoryUsecase interface {
+	List() ([]*model.Category, error)
+	Create(actorID uuid.UUID, category *model.Category) (*model.Category, error)
+	Update(actorID uuid.UUID, category *model.Category) (*model.Category, error)
+	Delete(actorID, id uuid.UUID) error
+	GetThreads(id, viewerID uuid.UUID, limit, offset int) (*model.Category, []*model.Thread, error)
+}
+
+type CategoryRepository interface {
+	List() ([]*model.Category, error)
+	GetByID(id uuid.UUID) (*model.Category, error)
+	GetBySlug(slug string) (*model.Category, error)
+	Create(category *model.Category) error
+	Update(category *model.Category) error
+	Delete(id uuid.UUID) error
+}
@@ -3,15 +3,28 @@ package usecase
 import (
 	"WebMessanger/internal/model"
 	"github.com/google/uuid"
+	"time"
 )
 
 type CommentUsecase interface {
 	Create(comment *model.Comment) (*model.Comment, error)
-	Delete(id uuid.UUID) error
-	GetByThread(threadID uuid.UUID) ([]*model.Comment, error)
+	Update(id, userID uuid.UUID, content string) (*model.Comment, error)
+	Delete(id, actorID uuid.UUID) error
+	GetDeleted(userID uuid.UUID) ([]*model.Comment, error)
+	Restore(id, userID uuid.UUID) (*model.Comment, error)
+	GetByID(id uuid.UUID) (*model.Comment, error)
+	GetByThread(threadID, viewerID uuid.UUID, maxDepth, repliesLimit int) ([]*model.Comment, error)
+	GetReplies(threadID, commentID, viewerID uuid.UUID, offset, limit, maxDepth int) ([]*model.Comment, bool, error)
 }
 type CommentRepository interface {
 	Create(comment *model.Comment) (*model.Comment, error)
-	Delete(id uuid.UUID) error
-	GetByThread(threadID uuid.UUID) ([]*model.Comment, error)
+	Update(comment *model.Comment) error
+	Delete(id, deletedBy uuid.UUID) error
+	GetDeletedByUser(userID uuid.UUID, since time.Time) ([]*model.Comment, error)
+	Restore(id, userID uuid.UUID, since time.Time) (bool, error)
+	PurgeDeleted(before time.Time, limit int) (int, error)
+	GetByID(id uuid.UUID) (*model.Comment, error)
+	GetByThread(threadID, viewerID uuid.UUID) ([]*model.Comment, error)
+	GetUnrendered(limit int) ([]*model.Comment, error)
+	SetContentHTML(id uuid.UUID, contentHTML string) error
 }
@@ -0,0 +1,39 @@
+package usecase
+
+import (
+	"WebMessanger/internal/model"
+	"github.com/google/uuid"
+)
+
+type CommunityUsecase interface {
+	Create(community *model.Community) (*model.Community, error)
+	Get(id, viewerID uuid.UUID) (*model.Community, error)
+	List(query string, viewerID uuid.UUID, limit, offset int) ([]*model.Community, error)
+	Update(id, actorID uuid.UUID, name, description *string) (*model.Community, error)
+	Delete(id, actorID uuid.UUID) error
+	Join(id, userID uuid.UUID) error
+	Leave(id, userID uuid.UUID) error
+	GetMembers(id uuid.UUID, limit, offset int) ([]*model.CommunityMember, error)
+	RemoveMember(id, actorID, userID uuid.UUID) error
+	Unban(id, actorID, userID uuid.UUID) error
+	SetModerator(id, actorID, userID uuid.UUID, moderator bool) error
+	GetThreads(id, viewerID uuid.UUID, limit, offset int) ([]*model.Thread, error)
+	RemoveThread(id, actorID, threadID uuid.UUID) error
+}
+
+type CommunityRepository interface {
+	Create(community *model.Community) error
+	GetByID(id, viewerID uuid.UUID) (*model.Community, error)
+	GetByName(name string) (*model.Community, error)
+	List(query string, viewerID uuid.UUID, limit, offset int) ([]*model.Community, error)
+	Update(community *model.Community) error
+	Delete(id, deletedBy uuid.UUID) error
+	GetRole(communityID, userID uuid.UUID) (string, error)
+	AddMember(communityID, userID uuid.UUID, role string) error
+	RemoveMember(communityID, userID uuid.UUID) error
+	BanMember(communityID, userID, bannedBy uuid.UUID) error
+	Unban(communityID, userID uuid.UUID) (bool, error)
+	IsBanned(communityID, userID uuid.UUID) (bool, error)
+	SetRole(communityID, userID uuid.UUID, role string) error
+	GetMembers(communityID uuid.UUID, limit, offset int) ([]*model.CommunityMember, error)
+}
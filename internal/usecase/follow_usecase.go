@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"WebMessanger/internal/model"
+	"github.com/google/uuid"
+)
+
+type FollowUsecase interface {
+	Follow(followerID, followeeID uuid.UUID) error
+	Unfollow(followerID, followeeID uuid.UUID) error
+	GetFollowers(userID uuid.UUID) ([]*model.PublicUser, error)
+	GetFollowing(userID uuid.UUID) ([]*model.PublicUser, error)
+}
+
+type FollowRepository interface {
+	Follow(followerID, followeeID uuid.UUID) error
+	Unfollow(followerID, followeeID uuid.UUID) error
+	IsFollowing(followerID, followeeID uuid.UUID) (bool, error)
+	GetFollowers(userID uuid.UUID) ([]*model.PublicUser, error)
+	GetFollowing(userID uuid.UUID) ([]*model.PublicUser, error)
+}
@@ -7,14 +7,22 @@ import (
 
 type LikeUsecase interface {
 	AddLike(like *model.Like) (*model.Like, error)
-	RemoveLike(threadID uuid.UUID, userID uuid.UUID) error
-	GetLikesByThread(threadID uuid.UUID) ([]*model.Like, error)
+	SetReaction(like *model.Like) (*model.Like, error)
+	RemoveLike(targetType string, targetID uuid.UUID, userID uuid.UUID) error
+	RemoveReaction(targetType string, targetID uuid.UUID, userID uuid.UUID) error
+	GetLikesByThread(threadID, viewerID uuid.UUID) ([]*model.Like, error)
+	GetLikesByComment(commentID, viewerID uuid.UUID) ([]*model.Like, error)
 	GetLikesByUser(userID uuid.UUID) ([]*model.Like, error)
+	GetReactionSummary(targetType string, targetID uuid.UUID, viewerID uuid.UUID) (*model.ReactionSummary, error)
+	AllowedReactions() []string
 }
 
 type LikeRepository interface {
 	AddLike(like *model.Like) error
-	RemoveLike(threadID uuid.UUID, userID uuid.UUID) error
-	GetLikesByThread(threadID uuid.UUID) ([]*model.Like, error)
+	SetReaction(like *model.Like) error
+	RemoveLike(targetType string, targetID uuid.UUID, userID uuid.UUID, reaction string) error
+	GetLikesByTarget(targetType string, targetID uuid.UUID) ([]*model.Like, error)
 	GetLikesByUser(userID uuid.UUID) ([]*model.Like, error)
+	CountReactions(targetType string, targetID uuid.UUID) (map[string]int, error)
+	GetUserReaction(targetType string, targetID uuid.UUID, userID uuid.UUID) (string, error)
 }
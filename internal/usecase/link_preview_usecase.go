@@ -0,0 +1,8 @@
+package usecase
+
+import "WebMessanger/internal/model"
+
+type LinkPreviewRepository interface {
+	Get(url string) (*model.LinkPreview, error)
+	Save(preview *model.LinkPreview) error
+}
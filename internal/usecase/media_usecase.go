@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"WebMessanger/internal/model"
+	"context"
+	"github.com/google/uuid"
+	"io"
+)
+
+type MediaUsecase interface {
+	Upload(ctx context.Context, kind string, userID uuid.UUID, file io.Reader, size int64) (*model.Media, error)
+	Discard(ctx context.Context, media *model.Media) error
+}
+
+type MediaRepository interface {
+	Save(userID uuid.UUID, media *model.Media) error
+	GetByURL(url string) (*model.Media, error)
+	Delete(url string) error
+}
+
+// Storage stores uploaded files under a key and returns their public URL.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error)
+	Delete(ctx context.Context, key string) error
+}
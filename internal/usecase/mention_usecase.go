@@ -0,0 +1,11 @@
+package usecase
+
+import (
+	"WebMessanger/internal/model"
+	"github.com/google/uuid"
+)
+
+type MentionRepository interface {
+	ReplaceForSource(sourceType string, sourceID uuid.UUID, mentions []*model.Mention) error
+	GetMentionedUserIDs(sourceType string, sourceID uuid.UUID) ([]uuid.UUID, error)
+}
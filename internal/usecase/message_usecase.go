@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"WebMessanger/internal/model"
+	"github.com/google/uuid"
+)
+
+type MessageUsecase interface {
+	StartConversation(userID, otherID uuid.UUID) (*model.Conversation, error)
+	GetConversations(userID uuid.UUID, limit, offset int) ([]*model.Conversation, error)
+	Send(conversationID, senderID uuid.UUID, content string) (*model.Message, error)
+	GetMessages(conversationID, userID uuid.UUID, cursor string, limit int) ([]*model.Message, string, error)
+	MarkRead(conversationID, userID uuid.UUID) error
+}
+
+type MessageRepository interface {
+	GetOrCreateConversation(startedBy, otherID uuid.UUID) (uuid.UUID, error)
+	GetConversation(id, userID uuid.UUID) (*model.Conversation, error)
+	GetConversations(userID uuid.UUID, limit, offset int) ([]*model.Conversation, error)
+	AddMessage(message *model.Message) error
+	GetMessages(conversationID uuid.UUID, before *model.MessageCursor, limit int) ([]*model.Message, error)
+	MarkRead(conversationID, userID uuid.UUID) error
+}
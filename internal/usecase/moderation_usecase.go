@@ -0,0 +1,12 @@
+package usecase
+
+import (
+	"WebMessanger/internal/model"
+	"github.com/google/uuid"
+)
+
+type ModerationUsecase interface {
+	SetSensitive(threadID, actorID uuid.UUID, sensitive bool, contentWarning string) (*model.Thread, error)
+	SetLocked(threadID, actorID uuid.UUID, locked bool) (*model.Thread, error)
+	GetAuditLog(threadID, actorID uuid.UUID) ([]*model.AuditEntry, error)
+}
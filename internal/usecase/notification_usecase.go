@@ -0,0 +1,21 @@
+package usecase
+
+import (
+	"WebMessanger/internal/model"
+	"github.com/google/uuid"
+)
+
+type NotificationUsecase interface {
+	GetByUser(userID uuid.UUID, limit, offset int) ([]*model.Notification, error)
+	CountUnread(userID uuid.UUID) (int, error)
+	MarkRead(id, userID uuid.UUID) error
+	MarkAllRead(userID uuid.UUID) error
+}
+
+type NotificationRepository interface {
+	Create(notification *model.Notification) error
+	GetByUser(userID uuid.UUID, limit, offset int) ([]*model.Notification, error)
+	CountUnread(userID uuid.UUID) (int, error)
+	MarkRead(id, userID uuid.UUID) error
+	MarkAllRead(userID uuid.UUID) error
+}
@@ -0,0 +1,16 @@
+package usecase
+
+import "github.com/google/uuid"
+
+type PinUsecase interface {
+	Pin(threadID, userID uuid.UUID) error
+	Unpin(threadID, userID uuid.UUID) error
+	Reorder(userID uuid.UUID, threadIDs []uuid.UUID) error
+}
+
+type PinRepository interface {
+	GetPinnedIDs(userID uuid.UUID) ([]uuid.UUID, error)
+	Pin(userID, threadID uuid.UUID, limit int) (bool, error)
+	Unpin(userID, threadID uuid.UUID) error
+	Reorder(userID uuid.UUID, threadIDs []uuid.UUID) error
+}
@@ -0,0 +1,17 @@
+package usecase
+
+import (
+	"WebMessanger/internal/model"
+	"github.com/google/uuid"
+)
+
+type PollUsecase interface {
+	Vote(threadID, userID uuid.UUID, optionIDs []uuid.UUID) (*model.Poll, error)
+	ChangeVote(threadID, userID uuid.UUID, optionIDs []uuid.UUID) (*model.Poll, error)
+}
+
+type PollRepository interface {
+	Create(threadID uuid.UUID, poll *model.Poll) error
+	GetByThread(threadID, viewerID uuid.UUID) (*model.Poll, error)
+	ReplaceVotes(pollID, userID uuid.UUID, optionIDs []uuid.UUID) error
+}
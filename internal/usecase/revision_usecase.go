@@ -0,0 +1,15 @@
+package usecase
+
+import (
+	"WebMessanger/internal/model"
+	"github.com/google/uuid"
+)
+
+type RevisionUsecase interface {
+	GetThreadRevisions(threadID, viewerID uuid.UUID) ([]*model.Revision, error)
+	GetCommentRevisions(commentID, viewerID uuid.UUID) ([]*model.Revision, error)
+}
+
+type RevisionRepository interface {
+	GetByTarget(targetType string, targetID uuid.UUID) ([]*model.Revision, error)
+}
@@ -0,0 +1,16 @@
+package usecase
+
+import (
+	"WebMessanger/internal/model"
+	"github.com/google/uuid"
+)
+
+type TagUsecase interface {
+	GetThreadsByTag(tag string, viewerID uuid.UUID, limit, offset int) ([]*model.Thread, error)
+	Autocomplete(prefix string, limit int) ([]*model.Tag, error)
+}
+
+type TagRepository interface {
+	SetThreadTags(threadID uuid.UUID, tags []string) error
+	Autocomplete(prefix string, limit int) ([]*model.Tag, error)
+}
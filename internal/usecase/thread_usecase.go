@@ -3,22 +3,46 @@ package usecase
 import (
 	"WebMessanger/internal/model"
 	"github.com/google/uuid"
+	"time"
 )
 
 type ThreadUsecase interface {
 	Create(thread *model.Thread) (*model.Thread, error)
-	GetAllThreads() ([]*model.Thread, error)
-	GetThreadById(id uuid.UUID) (*model.Thread, error)
+	GetAllThreads(viewerID uuid.UUID) ([]*model.Thread, error)
+	GetThreadById(id, viewerID uuid.UUID) (*model.Thread, error)
 	Update(thread *model.Thread) (*model.Thread, error)
-	Delete(id uuid.UUID) error
-	GetByUser(userID uuid.UUID) ([]*model.Thread, error)
+	Delete(id, actorID uuid.UUID) error
+	GetDeleted(userID uuid.UUID) ([]*model.Thread, error)
+	Restore(id, userID uuid.UUID) (*model.Thread, error)
+	GetByUser(userID, viewerID uuid.UUID) ([]*model.Thread, error)
+	Repost(threadID, userID uuid.UUID) error
+	Unrepost(threadID, userID uuid.UUID) error
+	GetDrafts(userID uuid.UUID) ([]*model.Thread, error)
+	Publish(id, userID uuid.UUID, publishAt *time.Time) (*model.Thread, error)
 }
 
 type ThreadRepository interface {
 	Create(thread *model.Thread) (*model.Thread, error)
-	GetAllThreads() ([]*model.Thread, error)
-	GetThreadById(id uuid.UUID) (*model.Thread, error)
+	GetAllThreads(viewerID uuid.UUID) ([]*model.Thread, error)
+	GetThreadById(id, viewerID uuid.UUID) (*model.Thread, error)
+	GetForModeration(id, moderatorID uuid.UUID) (*model.Thread, error)
 	Update(thread *model.Thread) (*model.Thread, error)
-	Delete(id uuid.UUID) error
-	GetByUser(userID uuid.UUID) ([]*model.Thread, error)
+	Delete(id, deletedBy uuid.UUID) error
+	GetDeletedByUser(userID uuid.UUID, since time.Time) ([]*model.Thread, error)
+	Restore(id, userID uuid.UUID, since time.Time) (bool, error)
+	GetByUser(userID, viewerID uuid.UUID) ([]*model.Thread, error)
+	GetByTag(tag string, viewerID uuid.UUID, limit, offset int) ([]*model.Thread, error)
+	GetByCommunity(communityID, viewerID uuid.UUID, limit, offset int) ([]*model.Thread, error)
+	GetByCategory(categoryID, viewerID uuid.UUID, limit, offset int) ([]*model.Thread, error)
+	AddRepost(id, threadID, userID uuid.UUID, createdAt time.Time) error
+	RemoveRepost(threadID, userID uuid.UUID) error
+	GetDrafts(userID uuid.UUID) ([]*model.Thread, error)
+	SetStatus(id uuid.UUID, status string, publishAt *time.Time, createdAt time.Time) error
+	PublishDue(now time.Time, limit int) ([]*model.Thread, error)
+	DeleteExpired(now time.Time, limit int) (int, error)
+	PurgeDeleted(before time.Time, limit int) (int, error)
+	SetSensitive(id uuid.UUID, sensitive bool, contentWarning string, byModerator bool) error
+	SetLocked(id uuid.UUID, locked bool, lockedBy *uuid.UUID, lockedAt *time.Time, entry *model.AuditEntry) error
+	GetUnrendered(limit int) ([]*model.Thread, error)
+	SetContentHTML(id uuid.UUID, contentHTML string) error
 }
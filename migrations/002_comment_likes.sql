@@ -0,0 +1,13 @@
+ALTER TABLE likes
+    ADD COLUMN IF NOT EXISTS target_type TEXT NOT NULL DEFAULT 'thread',
+    ADD COLUMN IF NOT EXISTS target_id UUID;
+
+UPDATE likes SET target_id = thread_id WHERE target_id IS NULL;
+
+ALTER TABLE likes
+    ALTER COLUMN target_id SET NOT NULL,
+    ALTER COLUMN thread_id DROP NOT NULL,
+    DROP CONSTRAINT IF EXISTS likes_target_type_check,
+    ADD CONSTRAINT likes_target_type_check CHECK (target_type IN ('thread', 'comment'));
+
+CREATE UNIQUE INDEX IF NOT EXISTS likes_target_user_key ON likes (target_type, target_id, user_id);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id   UUID PRIMARY KEY,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS thread_tags (
+    thread_id UUID NOT NULL REFERENCES threads (id) ON DELETE CASCADE,
+    tag_id    UUID NOT NULL REFERENCES tags (id) ON DELETE CASCADE,
+    PRIMARY KEY (thread_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_thread_tags_tag_id ON thread_tags (tag_id);
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS mentions (
+    id           UUID PRIMARY KEY,
+    source_type  TEXT        NOT NULL CHECK (source_type IN ('thread', 'comment')),
+    source_id    UUID        NOT NULL,
+    user_id      UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    start_offset INT         NOT NULL,
+    length       INT         NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_mentions_source ON mentions (source_type, source_id);
+CREATE INDEX IF NOT EXISTS idx_mentions_user_id ON mentions (user_id);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id         UUID PRIMARY KEY,
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    actor_id   UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    type       TEXT        NOT NULL,
+    thread_id  UUID REFERENCES threads (id) ON DELETE CASCADE,
+    comment_id UUID REFERENCES comments (id) ON DELETE CASCADE,
+    is_read    BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_id ON notifications (user_id, created_at DESC);
@@ -0,0 +1,15 @@
+-- quote_of_id deliberately has no foreign key: a quote outlives its original
+-- and is rendered as a "post unavailable" stub once the original is gone.
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS quote_of_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_threads_quote_of_id ON threads (quote_of_id);
+
+CREATE TABLE IF NOT EXISTS reposts (
+    id         UUID PRIMARY KEY,
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    thread_id  UUID        NOT NULL REFERENCES threads (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, thread_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_reposts_thread_id ON reposts (thread_id);
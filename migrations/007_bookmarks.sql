@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS bookmarks (
+    id         UUID PRIMARY KEY,
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    thread_id  UUID        NOT NULL REFERENCES threads (id) ON DELETE CASCADE,
+    folder     TEXT        NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, thread_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_bookmarks_user_created ON bookmarks (user_id, created_at DESC, id DESC);
@@ -0,0 +1,24 @@
+CREATE TABLE IF NOT EXISTS polls (
+    id        UUID PRIMARY KEY,
+    thread_id UUID    NOT NULL UNIQUE REFERENCES threads (id) ON DELETE CASCADE,
+    multiple  BOOLEAN NOT NULL DEFAULT FALSE,
+    closes_at TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS poll_options (
+    id       UUID PRIMARY KEY,
+    poll_id  UUID NOT NULL REFERENCES polls (id) ON DELETE CASCADE,
+    position INT  NOT NULL,
+    text     TEXT NOT NULL,
+    UNIQUE (poll_id, position)
+);
+
+CREATE TABLE IF NOT EXISTS poll_votes (
+    poll_id    UUID        NOT NULL REFERENCES polls (id) ON DELETE CASCADE,
+    option_id  UUID        NOT NULL REFERENCES poll_options (id) ON DELETE CASCADE,
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (option_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_poll_votes_poll_user ON poll_votes (poll_id, user_id);
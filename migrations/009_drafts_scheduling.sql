@@ -0,0 +1,7 @@
+ALTER TABLE threads
+    ADD COLUMN IF NOT EXISTS status     TEXT NOT NULL DEFAULT 'published',
+    ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ,
+    DROP CONSTRAINT IF EXISTS threads_status_check,
+    ADD CONSTRAINT threads_status_check CHECK (status IN ('published', 'draft', 'scheduled'));
+
+CREATE INDEX IF NOT EXISTS idx_threads_scheduled ON threads (publish_at) WHERE status = 'scheduled';
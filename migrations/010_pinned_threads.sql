@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS pinned_threads (
+    user_id   UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    thread_id UUID        NOT NULL REFERENCES threads (id) ON DELETE CASCADE,
+    position  INT         NOT NULL,
+    pinned_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, thread_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_pinned_threads_thread_id ON pinned_threads (thread_id);
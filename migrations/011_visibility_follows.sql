@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS follows (
+    follower_id UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    followee_id UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_follows_followee_id ON follows (followee_id);
+
+ALTER TABLE threads
+    ADD COLUMN IF NOT EXISTS visibility   TEXT NOT NULL DEFAULT 'public',
+    ADD COLUMN IF NOT EXISTS reply_policy TEXT NOT NULL DEFAULT 'everyone',
+    DROP CONSTRAINT IF EXISTS threads_visibility_check,
+    DROP CONSTRAINT IF EXISTS threads_reply_policy_check,
+    ADD CONSTRAINT threads_visibility_check CHECK (visibility IN ('public', 'followers', 'mentioned')),
+    ADD CONSTRAINT threads_reply_policy_check CHECK (reply_policy IN ('everyone', 'followers', 'mentioned', 'nobody'));
@@ -0,0 +1,8 @@
+-- content_html caches the sanitized rendering of the Markdown source. It is
+-- NULL for rows written before rendering existed until the startup backfill
+-- reaches them.
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS content_html TEXT;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS content_html TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_threads_content_html_missing ON threads (id) WHERE content_html IS NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_content_html_missing ON comments (id) WHERE content_html IS NULL;
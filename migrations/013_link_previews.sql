@@ -0,0 +1,13 @@
+-- link_previews caches unfurled OpenGraph metadata by URL. Failed fetches are
+-- cached too so a broken link is not retried on every post.
+CREATE TABLE IF NOT EXISTS link_previews (
+    url         TEXT PRIMARY KEY,
+    title       TEXT        NOT NULL DEFAULT '',
+    description TEXT        NOT NULL DEFAULT '',
+    image_url   TEXT        NOT NULL DEFAULT '',
+    site_name   TEXT        NOT NULL DEFAULT '',
+    failed      BOOLEAN     NOT NULL DEFAULT FALSE,
+    fetched_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS preview_url TEXT;
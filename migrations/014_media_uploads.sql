@@ -0,0 +1,13 @@
+-- media_uploads records every processed upload so thread and user reads can
+-- attach the resized variants of media_url and avatar_url.
+CREATE TABLE IF NOT EXISTS media_uploads (
+    url          TEXT PRIMARY KEY,
+    user_id      UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind         TEXT        NOT NULL,
+    content_type TEXT        NOT NULL,
+    size         BIGINT      NOT NULL,
+    variants     JSONB       NOT NULL DEFAULT '{}',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_media_uploads_user_id ON media_uploads (user_id);
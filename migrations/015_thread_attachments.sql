@@ -0,0 +1,32 @@
+-- Threads carry an ordered list of attachments instead of a single
+-- media_url. Responses still expose media_url, taken from the first one.
+CREATE TABLE IF NOT EXISTS thread_attachments (
+    id        UUID PRIMARY KEY,
+    thread_id UUID NOT NULL REFERENCES threads (id) ON DELETE CASCADE,
+    position  INT  NOT NULL,
+    type      TEXT NOT NULL CHECK (type IN ('image', 'video')),
+    url       TEXT NOT NULL,
+    width     INT,
+    height    INT,
+    alt_text  TEXT NOT NULL DEFAULT '',
+    UNIQUE (thread_id, position)
+);
+
+ALTER TABLE media_uploads
+    ADD COLUMN IF NOT EXISTS width  INT,
+    ADD COLUMN IF NOT EXISTS height INT;
+
+INSERT INTO thread_attachments (id, thread_id, position, type, url)
+SELECT gen_random_uuid(), t.id, 0,
+       CASE
+           WHEN mu.content_type LIKE 'video/%' THEN 'video'
+           WHEN mu.content_type IS NULL AND t.media_url ~* '\.(mp4|webm|mov|m4v)(\?.*)?$' THEN 'video'
+           ELSE 'image'
+       END,
+       t.media_url
+FROM threads t
+LEFT JOIN media_uploads mu ON mu.url = t.media_url
+WHERE COALESCE(t.media_url, '') <> ''
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE threads DROP COLUMN IF EXISTS media_url;
@@ -0,0 +1,15 @@
+-- Sensitive-media flags on threads, the per-user display preference, and user
+-- roles. Roles are granted directly in the database, e.g.
+--   UPDATE users SET role = 'moderator' WHERE name = '...';
+ALTER TABLE threads
+    ADD COLUMN IF NOT EXISTS sensitive            BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS content_warning      TEXT    NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS flagged_by_moderator BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS sensitive_media TEXT NOT NULL DEFAULT 'blur',
+    ADD COLUMN IF NOT EXISTS role            TEXT NOT NULL DEFAULT 'user',
+    DROP CONSTRAINT IF EXISTS users_sensitive_media_check,
+    DROP CONSTRAINT IF EXISTS users_role_check,
+    ADD CONSTRAINT users_sensitive_media_check CHECK (sensitive_media IN ('show', 'blur', 'hide')),
+    ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'moderator', 'admin'));
@@ -0,0 +1,7 @@
+-- Story-style threads that disappear after a TTL. ttl_seconds is what the
+-- author chose; expires_at is filled in once the thread is published.
+ALTER TABLE threads
+    ADD COLUMN IF NOT EXISTS ttl_seconds INTEGER,
+    ADD COLUMN IF NOT EXISTS expires_at  TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_threads_expires_at ON threads (expires_at) WHERE expires_at IS NOT NULL;
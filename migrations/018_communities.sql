@@ -0,0 +1,28 @@
+-- Topic communities. The owner is also stored as a member with role 'owner'
+-- so membership checks need only one table.
+CREATE TABLE IF NOT EXISTS communities (
+    id          UUID PRIMARY KEY,
+    name        TEXT        NOT NULL,
+    description TEXT        NOT NULL DEFAULT '',
+    owner_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS communities_name_key ON communities (LOWER(name));
+
+CREATE TABLE IF NOT EXISTS community_members (
+    community_id UUID        NOT NULL REFERENCES communities (id) ON DELETE CASCADE,
+    user_id      UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role         TEXT        NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'moderator', 'member')),
+    joined_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (community_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_community_members_user_id ON community_members (user_id);
+
+-- Deleting a community deletes its threads in the application, together with
+-- their comments and likes, so the reference is not cascaded here.
+ALTER TABLE threads
+    ADD COLUMN IF NOT EXISTS community_id UUID REFERENCES communities (id);
+
+CREATE INDEX IF NOT EXISTS idx_threads_community_id ON threads (community_id, created_at DESC) WHERE community_id IS NOT NULL;
@@ -0,0 +1,16 @@
+-- Optional thread titles and the admin-managed category list for
+-- forum-style browsing.
+CREATE TABLE IF NOT EXISTS categories (
+    id          UUID PRIMARY KEY,
+    slug        TEXT        NOT NULL UNIQUE,
+    name        TEXT        NOT NULL,
+    description TEXT        NOT NULL DEFAULT '',
+    position    INT         NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE threads
+    ADD COLUMN IF NOT EXISTS title       TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS category_id UUID REFERENCES categories (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_threads_category_id ON threads (category_id, created_at DESC) WHERE category_id IS NOT NULL;
@@ -0,0 +1,17 @@
+-- Thread locking and an audit trail of moderation actions.
+ALTER TABLE threads
+    ADD COLUMN IF NOT EXISTS locked    BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS locked_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS locked_at TIMESTAMPTZ;
+
+-- Entries outlive their target, so target_id is not a foreign key.
+CREATE TABLE IF NOT EXISTS audit_log (
+    id          UUID PRIMARY KEY,
+    actor_id    UUID        REFERENCES users (id) ON DELETE SET NULL,
+    action      TEXT        NOT NULL,
+    target_type TEXT        NOT NULL,
+    target_id   UUID        NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_target ON audit_log (target_type, target_id, created_at DESC);
@@ -0,0 +1,15 @@
+-- Edit history. Each row holds a version of a thread or comment that was
+-- replaced by an edit; created_at is when that version was written.
+ALTER TABLE threads ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS edited_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS revisions (
+    id          UUID PRIMARY KEY,
+    target_type TEXT        NOT NULL CHECK (target_type IN ('thread', 'comment')),
+    target_id   UUID        NOT NULL,
+    title       TEXT        NOT NULL DEFAULT '',
+    content     TEXT        NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_revisions_target ON revisions (target_type, target_id, created_at);
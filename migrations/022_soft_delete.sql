@@ -0,0 +1,16 @@
+-- Soft deletion. Deleted threads and comments stay in place, hidden from
+-- reads, until the purge job removes them after the restore window.
+ALTER TABLE threads
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS deleted_by UUID REFERENCES users (id) ON DELETE SET NULL;
+
+ALTER TABLE comments
+    ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ,
+    ADD COLUMN IF NOT EXISTS deleted_by UUID REFERENCES users (id) ON DELETE SET NULL;
+
+-- Placeholders left by the old delete have nothing to restore; date them so
+-- the purge job removes them once their replies are gone.
+UPDATE comments SET deleted_at = created_at WHERE is_deleted AND deleted_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_threads_deleted_at ON threads (deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_deleted_at ON comments (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,38 @@
+-- Direct messages between two users, and blocks between users.
+CREATE TABLE IF NOT EXISTS blocks (
+    blocker_id UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    blocked_id UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (blocker_id, blocked_id)
+);
+
+-- user_a is always the lower id, so each pair of users has one conversation.
+CREATE TABLE IF NOT EXISTS conversations (
+    id              UUID PRIMARY KEY,
+    user_a          UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    user_b          UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    started_by      UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_message_at TIMESTAMPTZ,
+    CHECK (user_a < user_b),
+    UNIQUE (user_a, user_b)
+);
+
+-- One row per participant, holding how far they have read.
+CREATE TABLE IF NOT EXISTS conversation_members (
+    conversation_id UUID NOT NULL REFERENCES conversations (id) ON DELETE CASCADE,
+    user_id         UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    last_read_at    TIMESTAMPTZ,
+    PRIMARY KEY (conversation_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS messages (
+    id              UUID PRIMARY KEY,
+    conversation_id UUID        NOT NULL REFERENCES conversations (id) ON DELETE CASCADE,
+    sender_id       UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    content         TEXT        NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_conversation_members_user ON conversation_members (user_id);
+CREATE INDEX IF NOT EXISTS idx_messages_conversation ON messages (conversation_id, created_at DESC, id DESC);
@@ -0,0 +1,7 @@
+-- Deleting a community now soft-deletes its threads, which outlive the
+-- community row until the purge job removes them, so the reference must not
+-- block the delete.
+ALTER TABLE threads DROP CONSTRAINT IF EXISTS threads_community_id_fkey;
+ALTER TABLE threads
+    ADD CONSTRAINT threads_community_id_fkey
+    FOREIGN KEY (community_id) REFERENCES communities (id) ON DELETE SET NULL;
@@ -0,0 +1,9 @@
+-- Members removed by a moderator are banned so they cannot join again until
+-- a moderator lifts the ban.
+CREATE TABLE IF NOT EXISTS community_bans (
+    community_id UUID        NOT NULL REFERENCES communities (id) ON DELETE CASCADE,
+    user_id      UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    banned_by    UUID        REFERENCES users (id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (community_id, user_id)
+);
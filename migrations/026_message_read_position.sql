@@ -0,0 +1,32 @@
+-- Read positions are tracked by a per-message sequence number instead of a
+-- timestamp: last_read_at was compared with created_at values set by the
+-- application, so clock skew between servers could hide or resurrect unread
+-- messages. Sequence numbers are assigned by the database in insert order.
+CREATE SEQUENCE IF NOT EXISTS messages_seq;
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS seq BIGINT;
+
+UPDATE messages m SET seq = numbered.n
+FROM (SELECT id, ROW_NUMBER() OVER (ORDER BY created_at, id) AS n FROM messages) numbered
+WHERE m.id = numbered.id AND m.seq IS NULL;
+
+SELECT setval('messages_seq', COALESCE((SELECT MAX(seq) FROM messages), 0) + 1, false);
+
+ALTER TABLE messages
+    ALTER COLUMN seq SET DEFAULT nextval('messages_seq'),
+    ALTER COLUMN seq SET NOT NULL;
+ALTER SEQUENCE messages_seq OWNED BY messages.seq;
+
+CREATE INDEX IF NOT EXISTS idx_messages_conversation_seq ON messages (conversation_id, seq);
+
+ALTER TABLE conversation_members
+    ADD COLUMN IF NOT EXISTS last_read_seq BIGINT NOT NULL DEFAULT 0;
+
+UPDATE conversation_members cm
+SET last_read_seq = COALESCE((
+    SELECT MAX(m.seq) FROM messages m
+    WHERE m.conversation_id = cm.conversation_id AND m.created_at <= cm.last_read_at
+), 0)
+WHERE cm.last_read_at IS NOT NULL;
+
+ALTER TABLE conversation_members DROP COLUMN IF EXISTS last_read_at;